@@ -0,0 +1,93 @@
+package main
+
+import (
+	"lets-play-rummikub/internal/command"
+	"slices"
+	"strings"
+)
+
+// clientCommands are handled by this client rather than sent to the server.
+var clientCommands = []string{"say", "tell", "help", "ids", "quit", "exit"}
+
+// completions returns what the last word of the line could be completed to:
+// a command name for the first word, a player for tell, and the notation of
+// the tiles in the rack for a command's piece arguments.
+func (s state) completions(line string) []string {
+	words := strings.Split(line, " ")
+	last := words[len(words)-1]
+	var options []string
+	switch {
+	case len(words) == 1:
+		options = append(command.Commands(), clientCommands...)
+	case words[0] == "tell" && len(words) == 2:
+		for _, player := range s.Players {
+			options = append(options, player.Name)
+		}
+	default:
+		name, _ := expand(words[0])
+		if takesPiece(command.Grammar[name], len(words)-2) {
+			for _, p := range s.Rack {
+				options = append(options, p.piece().Notation())
+			}
+		}
+	}
+	matches := make([]string, 0)
+	for _, option := range options {
+		if strings.HasPrefix(option, last) && !slices.Contains(matches, option) {
+			matches = append(matches, option)
+		}
+	}
+	slices.Sort(matches)
+	return matches
+}
+
+// takesPiece reports whether the argument at the index is a piece from the
+// rack. A pieces argument takes every word from its index on.
+func takesPiece(arguments []command.Argument, index int) bool {
+	if len(arguments) == 0 {
+		return false
+	}
+	if index >= len(arguments) {
+		index = len(arguments) - 1
+		if arguments[index].Kind != command.PiecesArgument {
+			return false
+		}
+	}
+	kind := arguments[index].Kind
+	return kind == command.PieceArgument || kind == command.PiecesArgument
+}
+
+// autoComplete completes the word before the cursor when Tab is pressed, as
+// far as every completion agrees, and lists them when that is no further.
+func (c *client) autoComplete(line string, pos int, key rune) (string, int, bool) {
+	if key != '\t' {
+		return "", 0, false
+	}
+	var latest state
+	if snapshot := c.latest.Load(); snapshot != nil {
+		latest = *snapshot
+	}
+	matches := latest.completions(line[:pos])
+	if len(matches) == 0 {
+		return line, pos, true
+	}
+	start := strings.LastIndex(line[:pos], " ") + 1
+	completed := commonPrefix(matches)
+	if len(matches) == 1 {
+		completed += " "
+	} else if completed == line[start:pos] {
+		c.println(strings.Join(matches, "  "))
+	}
+	return line[:start] + completed + line[pos:], start + len(completed), true
+}
+
+// commonPrefix returns the longest prefix the words share.
+func commonPrefix(words []string) string {
+	prefix := words[0]
+	for _, word := range words[1:] {
+		for !strings.HasPrefix(word, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return prefix
+}
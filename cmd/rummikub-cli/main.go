@@ -0,0 +1,327 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"lets-play-rummikub/internal/command"
+	"lets-play-rummikub/internal/server"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gorilla/websocket"
+	"golang.org/x/term"
+)
+
+type client struct {
+	conn    *websocket.Conn
+	room    string
+	state   state
+	showIDs bool
+	seq     uint64
+	output  sync.Mutex
+	// out is stdout, or the line editor writing to it when stdin is a
+	// terminal, so that messages do not garble the line being typed.
+	out io.Writer
+	// latest is a copy of state for completion, which runs while a
+	// message may be holding output.
+	latest atomic.Pointer[state]
+}
+
+func (c *client) println(a ...any) {
+	c.output.Lock()
+	defer c.output.Unlock()
+	fmt.Fprintln(c.out, a...)
+}
+
+// handle applies one envelope from the server to the local state or prints
+// its text.
+func (c *client) handle(message []byte) {
+	var envelope server.Envelope
+	if err := json.Unmarshal(message, &envelope); err != nil {
+		c.println(string(message))
+		return
+	}
+	switch envelope.Type {
+	case server.StateMessage:
+		var update state
+		if err := json.Unmarshal(envelope.Payload, &update); err == nil {
+			c.update(func() { c.state = update })
+		}
+	case server.SessionMessage:
+		var session server.SessionPayload
+		if err := json.Unmarshal(envelope.Payload, &session); err == nil && session.Token == "" {
+			c.println("watching room", c.room, "as a spectator")
+		} else if err == nil {
+			c.println(fmt.Sprintf("joined seat %d, rejoin with -room %s -token %s", session.Seat+1, c.room, session.Token))
+			if session.Host {
+				c.println("you are the host")
+			}
+		}
+	case server.ChatMessage:
+		var chat server.ChatPayload
+		if err := json.Unmarshal(envelope.Payload, &chat); err == nil {
+			from := chat.From
+			if chat.To != "" {
+				from = from + " to " + chat.To
+			}
+			c.println(fmt.Sprintf("[%s] %s: %s", chat.Time.Local().Format("15:04"), from, chat.Text))
+		}
+	case server.PhaseMessage:
+		var phase server.PhasePayload
+		if err := json.Unmarshal(envelope.Payload, &phase); err == nil {
+			c.println("game is", phase.Phase)
+		}
+	case server.AckMessage:
+	case server.NackMessage:
+		var nack server.NackPayload
+		if err := json.Unmarshal(envelope.Payload, &nack); err == nil {
+			c.println(fmt.Sprintf("%s rejected (%s): %s", nack.Command, nack.Code, nack.Message))
+		}
+	default:
+		var text server.TextPayload
+		if err := json.Unmarshal(envelope.Payload, &text); err == nil {
+			c.println(strings.TrimSpace(text.Text))
+		}
+	}
+}
+
+func (c *client) update(apply func()) {
+	c.output.Lock()
+	defer c.output.Unlock()
+	apply()
+	latest := c.state
+	c.latest.Store(&latest)
+	c.state.render(c.out, c.showIDs)
+}
+
+func (c *client) readMessages(done chan<- struct{}) {
+	defer close(done)
+	for {
+		_, message, err := c.conn.ReadMessage()
+		if err != nil {
+			c.println("connection closed:", err)
+			return
+		}
+		c.handle(message)
+	}
+}
+
+// send wraps the payload in the next envelope from this client.
+func (c *client) send(messageType server.MessageType, payload any) error {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	c.seq++
+	id := strconv.FormatUint(c.seq, 10)
+	message, err := json.Marshal(server.Envelope{Version: server.ProtocolVersion, Type: messageType, Seq: c.seq, ID: id, Payload: raw})
+	if err != nil {
+		return err
+	}
+	return c.conn.WriteMessage(websocket.TextMessage, message)
+}
+
+// expand returns the command a unique prefix abbreviates, e.g. "ins" for
+// "insert", or the commands it could stand for when it is ambiguous.
+func expand(prefix string) (string, []string) {
+	matches := make([]string, 0)
+	for _, name := range command.Commands() {
+		if name == prefix {
+			return name, nil
+		}
+		if strings.HasPrefix(name, prefix) {
+			matches = append(matches, name)
+		}
+	}
+	if len(matches) == 1 {
+		return matches[0], nil
+	}
+	return "", matches
+}
+
+func (c *client) help() {
+	c.println("commands (Tab completes them and the tiles in your rack; a unique prefix such as ins for insert is enough):")
+	for _, name := range command.Commands() {
+		c.println("  " + command.Usage(name))
+	}
+	c.println("  say <message>, tell <player> <message>, help, ids, quit")
+	c.println("pieces: r0 (rack), p0 (loose pile), or tiles such as R7, B5.2, J")
+}
+
+// execute validates a line against the command grammar before sending it.
+func (c *client) execute(line string) error {
+	prefix, input, _ := strings.Cut(line, " ")
+	name, candidates := expand(prefix)
+	if name == "" {
+		if len(candidates) > 0 {
+			return fmt.Errorf("did you mean: %s", strings.Join(candidates, ", "))
+		}
+		return fmt.Errorf("unknown command %q, type help", prefix)
+	}
+	if _, err := command.Parse(name, input); err != nil {
+		if parseErr, ok := err.(*command.ParseError); ok {
+			return fmt.Errorf("%s: %s at column %d", command.Usage(name), parseErr.Err, len(prefix)+1+parseErr.Position)
+		}
+		return err
+	}
+	return c.send(server.CommandMessage, server.CommandPayload{Command: name, Input: strings.TrimSpace(input)})
+}
+
+// readLines sends each line returned by next until it fails, which it does
+// once input ends.
+func readLines(next func() (string, error), lines chan<- string) {
+	defer close(lines)
+	for {
+		line, err := next()
+		if err != nil && err != term.ErrPasteIndicator {
+			return
+		}
+		lines <- line
+	}
+}
+
+// scanLines reads lines from stdin when it is not a terminal.
+func scanLines() func() (string, error) {
+	scanner := bufio.NewScanner(os.Stdin)
+	return func() (string, error) {
+		if scanner.Scan() {
+			return scanner.Text(), nil
+		}
+		if err := scanner.Err(); err != nil {
+			return "", err
+		}
+		return "", io.EOF
+	}
+}
+
+// readCommands handles lines from next until the user quits, input ends or
+// the connection closes, without waiting for another line after a
+// disconnect.
+func (c *client) readCommands(done <-chan struct{}, next func() (string, error)) {
+	lines := make(chan string)
+	go readLines(next, lines)
+	for {
+		var line string
+		select {
+		case <-done:
+			return
+		case read, ok := <-lines:
+			if !ok {
+				return
+			}
+			line = strings.TrimSpace(read)
+		}
+		switch line {
+		case "":
+			continue
+		case "help":
+			c.help()
+		case "ids":
+			c.output.Lock()
+			c.showIDs = !c.showIDs
+			c.state.render(c.out, c.showIDs)
+			c.output.Unlock()
+		case "quit", "exit":
+			return
+		default:
+			if text, ok := strings.CutPrefix(line, "say "); ok {
+				if err := c.send(server.ChatMessage, server.ChatPayload{Text: text}); err != nil {
+					c.println(err)
+				}
+				continue
+			}
+			if whisper, ok := strings.CutPrefix(line, "tell "); ok {
+				to, text, _ := strings.Cut(whisper, " ")
+				if err := c.send(server.ChatMessage, server.ChatPayload{To: to, Text: text}); err != nil {
+					c.println(err)
+				}
+				continue
+			}
+			if err := c.execute(line); err != nil {
+				c.println(err)
+			}
+		}
+	}
+}
+
+// createRoom asks the lobby for a new room and returns it with the host's
+// session token.
+func createRoom(addr string, seats uint) (server.CreatedRoom, error) {
+	lobby := url.URL{Scheme: "http", Host: addr, Path: "/rooms"}
+	response, err := http.PostForm(lobby.String(), url.Values{"seats": {strconv.FormatUint(uint64(seats), 10)}})
+	if err != nil {
+		return server.CreatedRoom{}, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusCreated {
+		message, _ := io.ReadAll(response.Body)
+		return server.CreatedRoom{}, fmt.Errorf("%s", strings.TrimSpace(string(message)))
+	}
+	var room server.CreatedRoom
+	err = json.NewDecoder(response.Body).Decode(&room)
+	return room, err
+}
+
+func main() {
+	addr := flag.String("addr", "localhost:8080", "server address")
+	room := flag.String("room", "", "room to join, or empty to create one")
+	seats := flag.Uint("seats", 2, "number of seats when creating a room")
+	token := flag.String("token", "", "session token to reclaim a seat after reconnecting")
+	spectate := flag.Bool("spectate", false, "watch the room without taking a seat")
+	locale := flag.String("locale", "", "language for server messages, e.g. es or tl")
+	flag.Parse()
+
+	if *room == "" {
+		created, err := createRoom(*addr, *seats)
+		if err != nil {
+			fmt.Println("create room:", err)
+			os.Exit(1)
+		}
+		*room, *token = created.ID, created.Token
+		fmt.Println("created room", created.ID)
+	}
+	query := url.Values{}
+	if *locale != "" {
+		query.Set("locale", *locale)
+	}
+	if *token != "" {
+		query.Set("token", *token)
+	}
+	if *spectate {
+		query.Set("spectate", "true")
+	}
+	server := url.URL{Scheme: "ws", Host: *addr, Path: "/ws/" + url.PathEscape(*room), RawQuery: query.Encode()}
+	conn, _, err := websocket.DefaultDialer.Dial(server.String(), nil)
+	if err != nil {
+		fmt.Println("dial:", err)
+		os.Exit(1)
+	}
+	defer conn.Close()
+
+	c := &client{conn: conn, room: *room, out: os.Stdout}
+	next := scanLines()
+	if fd := int(os.Stdin.Fd()); term.IsTerminal(fd) {
+		if previous, err := term.MakeRaw(fd); err == nil {
+			defer term.Restore(fd, previous)
+			editor := term.NewTerminal(struct {
+				io.Reader
+				io.Writer
+			}{os.Stdin, os.Stdout}, "> ")
+			editor.AutoCompleteCallback = c.autoComplete
+			c.out, next = editor, editor.ReadLine
+		}
+	}
+	c.println("connected to", server.String(), "- type help for commands")
+	done := make(chan struct{})
+	go c.readMessages(done)
+	c.readCommands(done, next)
+	conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+}
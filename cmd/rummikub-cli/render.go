@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"lets-play-rummikub/internal/model"
+	"strings"
+)
+
+type (
+	pieceState struct {
+		ID    int    `json:"id"`
+		Value int    `json:"value"`
+		Color string `json:"color"`
+		Joker bool   `json:"joker"`
+	}
+
+	setState struct {
+		Pieces []pieceState `json:"pieces"`
+	}
+
+	seatState struct {
+		Name   string `json:"name"`
+		Rack   int    `json:"rack"`
+		Melded bool   `json:"melded"`
+	}
+
+	// state is the game as the server last showed it from our seat.
+	state struct {
+		Seat    int            `json:"seat"`
+		Turn    int            `json:"turn"`
+		Pool    int            `json:"pool"`
+		Board   []setState     `json:"board"`
+		Loose   []pieceState   `json:"piece"`
+		Rack    []pieceState   `json:"rack"`
+		Players []seatState    `json:"players"`
+		Racks   [][]pieceState `json:"racks"`
+	}
+)
+
+func (p pieceState) piece() model.Piece {
+	if p.Joker {
+		return model.NewPiece(model.ValueJoker, model.ColorBlack)
+	}
+	color, _ := model.ParseColor(p.Color)
+	return model.NewPiece(model.Value(p.Value), color)
+}
+
+func formatPieces(prefix string, pieces []pieceState, showIDs bool) string {
+	formatted := make([]string, len(pieces))
+	for i, p := range pieces {
+		formatted[i] = fmt.Sprintf("%s%d%s", prefix, i, p.piece().String())
+		if showIDs {
+			formatted[i] = formatted[i] + fmt.Sprintf("#%d", p.ID)
+		}
+	}
+	return strings.Join(formatted, " ")
+}
+
+func (s *state) render(w io.Writer, showIDs bool) {
+	fmt.Fprintln(w, "=== Players ===")
+	for i, player := range s.Players {
+		marker := " "
+		if i == s.Turn {
+			marker = ">"
+		}
+		status := ""
+		if player.Melded {
+			status = ", melded"
+		}
+		if i == s.Seat {
+			status = status + ", you"
+		}
+		fmt.Fprintf(w, "%s %s: %d pieces%s\n", marker, player.Name, player.Rack, status)
+		if i < len(s.Racks) {
+			fmt.Fprintf(w, "    %s\n", formatPieces("", s.Racks[i], showIDs))
+		}
+	}
+	fmt.Fprintf(w, "pool: %d pieces\n", s.Pool)
+	fmt.Fprintln(w, "=== Board ===")
+	for i, set := range s.Board {
+		fmt.Fprintf(w, "[%d] %s\n", i, formatPieces("", set.Pieces, showIDs))
+	}
+	if len(s.Loose) > 0 {
+		fmt.Fprintf(w, "=== Loose Pieces ===\n%s\n", formatPieces("p", s.Loose, showIDs))
+	}
+	if s.Seat >= 0 {
+		fmt.Fprintf(w, "=== Rack ===\n%s\n", formatPieces("r", s.Rack, showIDs))
+	}
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"lets-play-rummikub/internal/command"
+	"lets-play-rummikub/internal/constants"
+	"lets-play-rummikub/internal/history"
+	"lets-play-rummikub/internal/model"
+	"os"
+	"strings"
+)
+
+const clearScreen = "\x1b[2J\x1b[H"
+
+// table plays a game at a single machine, passing the device between seats.
+type table struct {
+	game    model.Game
+	history history.Stack[history.Undoable]
+	input   *bufio.Scanner
+}
+
+// Notify prints game messages, or redraws the table when the game only
+// signals that its state changed.
+func (t *table) Notify(messages ...constants.Message) {
+	if len(messages) == 0 {
+		t.render()
+		return
+	}
+	for _, message := range messages {
+		fmt.Println(strings.TrimSpace(message.Error()))
+	}
+}
+
+func (t *table) render() {
+	t.game.PrintBoard()
+	t.game.CurrentPlayer().PrintRack()
+}
+
+func (t *table) readLine(prompt string) (string, bool) {
+	fmt.Print(prompt)
+	if !t.input.Scan() {
+		return "", false
+	}
+	return strings.TrimSpace(t.input.Text()), true
+}
+
+// passDevice hides the previous player's rack until the next player is ready.
+func (t *table) passDevice() bool {
+	fmt.Print(clearScreen)
+	_, ok := t.readLine(fmt.Sprintf("Pass the device to %s and press Enter.", t.game.CurrentPlayer().Name()))
+	fmt.Print(clearScreen)
+	return ok
+}
+
+func (t *table) help() {
+	fmt.Println("commands:")
+	for _, name := range command.Commands() {
+		if command.TurnCommands[name] || name == "name" {
+			fmt.Println("  " + command.Usage(name))
+		}
+	}
+	fmt.Println("  help, quit")
+}
+
+// playTurn reads commands until the current player ends their turn. It
+// returns false when the players quit.
+func (t *table) playTurn() bool {
+	player := t.game.CurrentPlayer()
+	t.render()
+	for {
+		line, ok := t.readLine(fmt.Sprintf("%s> ", player.Name()))
+		if !ok {
+			return false
+		}
+		name, input, _ := strings.Cut(line, " ")
+		switch name {
+		case "":
+			continue
+		case "help":
+			t.help()
+		case "quit", "exit":
+			return false
+		case "undo":
+			if undo := t.history.Pop(); undo != nil {
+				undo.Undo()
+			}
+		case "end":
+			if err := t.game.NextTurn(); err != nil {
+				fmt.Println(err)
+				continue
+			}
+			t.history.Clear()
+			return true
+		case "draw":
+			if err := t.game.Draw(); err != nil {
+				fmt.Println(err)
+				continue
+			}
+			t.history.Clear()
+			return true
+		case "name":
+			command.SetName(player, strings.TrimSpace(input)).Invoke()
+		default:
+			playerCommand, err := command.New(name, player, t.game, input)
+			if err != nil {
+				fmt.Printf(constants.CommandError+"\n", name, err)
+				continue
+			}
+			if err := playerCommand.Invoke(); err != nil {
+				fmt.Printf(constants.CommandError+"\n", name, err)
+				continue
+			}
+			t.history.Push(playerCommand)
+		}
+	}
+}
+
+func (t *table) printScores() {
+	fmt.Println("Scores:")
+	for i := 0; i < t.game.TotalPlayers(); i++ {
+		player := t.game.Player(i)
+		fmt.Printf("%s: %d\n", player.Name(), player.Score())
+	}
+}
+
+// isGameOver reports whether the game has ended, announcing the winner when
+// a player went out.
+func (t *table) isGameOver() bool {
+	if !t.game.IsGameOver() {
+		return t.game.IsStalemate()
+	}
+	for i := 0; i < t.game.TotalPlayers(); i++ {
+		if t.game.Player(i).RackLen() == 0 {
+			fmt.Printf("%s wins!\n", t.game.Player(i).Name())
+		}
+	}
+	return true
+}
+
+func main() {
+	players := flag.Uint("players", 2, "number of players at the table")
+	flag.Parse()
+	if *players < 1 || *players > 4 {
+		fmt.Println("players must be between 1 and 4")
+		os.Exit(1)
+	}
+
+	t := &table{
+		game:    model.NewGame(*players),
+		history: history.NewStack[history.Undoable](),
+		input:   bufio.NewScanner(os.Stdin),
+	}
+	for i := 0; i < t.game.TotalPlayers(); i++ {
+		name, ok := t.readLine(fmt.Sprintf("Name for %s: ", t.game.Player(i).Name()))
+		if !ok {
+			return
+		}
+		if name != "" {
+			command.SetName(t.game.Player(i), name).Invoke()
+		}
+	}
+	t.game.Shuffle()
+	t.game.DealPieces()
+	t.game.SetNotifier(t)
+	for t.passDevice() && t.playTurn() {
+		if t.isGameOver() {
+			break
+		}
+	}
+	t.printScores()
+}
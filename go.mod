@@ -2,7 +2,12 @@ module lets-play-rummikub
 
 go 1.23.3
 
-require github.com/stretchr/testify v1.10.0
+require (
+	github.com/stretchr/testify v1.10.0
+	golang.org/x/term v0.30.0
+)
+
+require golang.org/x/sys v0.31.0 // indirect
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
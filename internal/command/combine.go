@@ -24,11 +24,12 @@ func (c *combine) Undo() {
 	c.game.Notify()
 }
 
-func (c *combine) Invoke() {
+func (c *combine) Invoke() error {
 	c.undoGame, c.undoPlayer = c.game.Clone(), c.player.Clone()
 	set := model.Combine(c.pieces...)
 	c.player.RemovePiece(c.pieces...)
 	c.game.RemovePieces(c.pieces...)
 	c.game.AddSet(set)
 	c.game.Notify()
+	return nil
 }
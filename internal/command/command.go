@@ -6,14 +6,34 @@ import (
 	"lets-play-rummikub/internal/history"
 	"lets-play-rummikub/internal/model"
 	"strconv"
-	"strings"
 )
 
 type Command interface {
-	Invoke()
+	Invoke() error
 	history.Undoable
 }
 
+// New builds the named command that changes the table from player input.
+func New(name string, player model.Player, game model.Game, input string) (Command, error) {
+	switch name {
+	case "combine":
+		return Combine(player, game, input)
+	case "insert":
+		return Insert(player, game, input)
+	case "remove":
+		return Remove(game, input)
+	case "split":
+		return Split(game, input)
+	case "move":
+		return Move(game, input)
+	case "splitinsert":
+		return SplitInsert(player, game, input)
+	case "rearrange":
+		return Rearrange(player, game, input)
+	}
+	return nil, errors.New(constants.InvalidCommand)
+}
+
 func parseInt(input string) (int, error) {
 	result, err := strconv.ParseInt(input, 0, 16)
 	if err != nil {
@@ -44,23 +64,97 @@ func getPieceFrom(from byte, options ...model.HasPiece) (model.HasPiece, error)
 	return nil, errors.New(constants.InvalidPieceSelection)
 }
 
+// findPiece looks up a piece written in tile notation, searching the options
+// in order so that the rack is preferred over the loose pile and the loose
+// pile over a set. Pieces in exclude have already been selected.
+func findPiece(token Token, game model.Game, exclude map[model.Piece]bool, options ...model.HasPiece) (model.Piece, error) {
+	for _, opt := range options {
+		for index := 0; ; index++ {
+			piece, err := opt.Piece(index)
+			if err != nil {
+				break
+			}
+			if exclude[piece] || !matchesNotation(token, piece) {
+				continue
+			}
+			if token.Copy != 0 && (game == nil || game.PieceCopy(piece) != token.Copy) {
+				continue
+			}
+			return piece, nil
+		}
+	}
+	return nil, parseError(token.Position, constants.InvalidPieceSelection)
+}
+
+func matchesNotation(token Token, piece model.Piece) bool {
+	if token.Value == model.ValueJoker {
+		return piece.IsJoker()
+	}
+	return !piece.IsJoker() && piece.Value() == token.Value && piece.Color() == token.Color
+}
+
+func selectPiece(token Token, game model.Game, exclude map[model.Piece]bool, options ...model.HasPiece) (model.Piece, error) {
+	if token.Notation {
+		return findPiece(token, game, exclude, options...)
+	}
+	selectPiece, err := getPieceFrom(token.Source, options...)
+	if err != nil {
+		return nil, &ParseError{token.Position, err}
+	}
+	piece, err := selectPiece.Piece(token.Number)
+	if err != nil {
+		return nil, parseError(token.Position, constants.InvalidPieceSelection)
+	}
+	return piece, nil
+}
+
+func selectSet(game model.Game, token Token) (model.Set, error) {
+	set, err := game.Set(token.Number)
+	if err != nil {
+		return nil, &ParseError{token.Position, err}
+	}
+	return set, nil
+}
+
+func selectSetPiece(game model.Game, set model.Set, token Token) (model.Piece, error) {
+	if token.Notation {
+		return findPiece(token, game, nil, set)
+	}
+	piece, err := set.Piece(token.Number)
+	if err != nil {
+		return nil, &ParseError{token.Position, err}
+	}
+	return piece, nil
+}
+
+// selectPosition resolves a position token against the set it refers to.
+func selectPosition(set model.Set, token Token) int {
+	if token.Text == endPosition {
+		return set.Len()
+	}
+	return token.Number
+}
+
 func parseSelectedPieces(input string, options ...model.HasPiece) ([]model.Piece, error) {
-	pieces := make([]model.Piece, 0)
-	for _, selection := range strings.Split(input, " ") {
-		from, pieceIndex := selection[0], selection[1:]
-		selectPiece, err := getPieceFrom(from, options...)
-		if err != nil {
-			return nil, err
+	tokens, err := parseArguments(Grammar["combine"], input)
+	if err != nil {
+		return nil, err
+	}
+	var game model.Game
+	for _, opt := range options {
+		if g, ok := opt.(model.Game); ok {
+			game = g
 		}
-		index, err := parseInt(pieceIndex)
+	}
+	pieces := make([]model.Piece, 0, len(tokens))
+	selected := make(map[model.Piece]bool)
+	for _, token := range tokens {
+		piece, err := selectPiece(token, game, selected, options...)
 		if err != nil {
 			return nil, err
 		}
-		if piece, err := selectPiece.Piece(index); err != nil {
-			return nil, err
-		} else {
-			pieces = append(pieces, piece)
-		}
+		selected[piece] = true
+		pieces = append(pieces, piece)
 	}
 	return pieces, nil
 }
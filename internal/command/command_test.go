@@ -108,3 +108,71 @@ func TestParseSelectedPieces(t *testing.T) {
 		assert.Nil(t, pieces)
 	})
 }
+
+func TestSelectPieceByNotation(t *testing.T) {
+	game := model.NewGame(1)
+	player := game.CurrentPlayer()
+	first, err := game.PieceByID(33)
+	assert.NoError(t, err)
+	second, err := game.PieceByID(86)
+	assert.NoError(t, err)
+	joker, err := game.PieceByID(53)
+	assert.NoError(t, err)
+	player.DealPiece(first)
+	game.AddLoosePiece(second)
+	game.AddLoosePiece(joker)
+	t.Run("ShouldPreferRack", func(t *testing.T) {
+		pieces, err := parseSelectedPieces("R7", player, game)
+		assert.NoError(t, err)
+		assert.Equal(t, pieces, []model.Piece{first})
+	})
+	t.Run("ShouldSelectEachDuplicateOnce", func(t *testing.T) {
+		pieces, err := parseSelectedPieces("R7 R7 J", player, game)
+		assert.NoError(t, err)
+		assert.Equal(t, pieces, []model.Piece{first, second, joker})
+	})
+	t.Run("ShouldSelectCopy", func(t *testing.T) {
+		pieces, err := parseSelectedPieces("R7.2", player, game)
+		assert.NoError(t, err)
+		assert.Equal(t, pieces, []model.Piece{second})
+	})
+	t.Run("ShouldReturnErrorOnMissingPiece", func(t *testing.T) {
+		pieces, err := parseSelectedPieces("R7 R7 R7", player, game)
+		assert.EqualError(t, err, constants.InvalidPieceSelection)
+		assert.Nil(t, pieces)
+	})
+}
+
+func TestNew(t *testing.T) {
+	game := model.NewGame(1)
+	player := game.CurrentPlayer()
+	setBoard(game, model.Combine(model.NewPiece(model.Value(1), model.ColorBlack), model.NewPiece(model.Value(2), model.ColorBlack), model.NewPiece(model.Value(3), model.ColorBlack), model.NewPiece(model.Value(4), model.ColorBlack)))
+	t.Run("ShouldReturnCommand", func(t *testing.T) {
+		command, err := New("split", player, game, "0 2")
+		assert.NoError(t, err)
+		assert.IsType(t, (*split)(nil), command)
+	})
+	t.Run("ShouldReturnErrorOnUnknownCommand", func(t *testing.T) {
+		command, err := New("undo", player, game, "")
+		assert.EqualError(t, err, constants.InvalidCommand)
+		assert.Nil(t, command)
+	})
+}
+
+func FuzzNew(f *testing.F) {
+	for _, seed := range []string{"r0 r1 r2", "0 r0 end", "0 R7.2", "0 1 1 start", "1,2,3;4,5,6|7", "", "-1 s-1 99999"} {
+		for _, name := range Commands() {
+			f.Add(name, seed)
+		}
+	}
+	f.Fuzz(func(t *testing.T, name, input string) {
+		game := model.NewGame(2)
+		game.DealPieces()
+		setBoard(game, model.Combine(model.NewPiece(1, model.ColorRed), model.NewPiece(2, model.ColorRed), model.NewPiece(3, model.ColorRed)))
+		setLoosePieces(game, model.NewPiece(5, model.ColorBlue))
+		command, err := New(name, game.Player(0), game, input)
+		if err == nil {
+			command.Invoke()
+		}
+	})
+}
@@ -0,0 +1,299 @@
+package command
+
+import (
+	"errors"
+	"lets-play-rummikub/internal/constants"
+	"lets-play-rummikub/internal/model"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+type (
+	ArgumentKind uint8
+
+	Argument struct {
+		Name string
+		Kind ArgumentKind
+	}
+
+	// Token is a single word of command input. Position is the 1-based
+	// column the token starts at. The remaining fields are filled in once
+	// the token is parsed as an argument: Number and Source for index
+	// selections, Value, Color and Copy for tile notation.
+	Token struct {
+		Text     string
+		Position int
+		Number   int
+		Source   byte
+		Notation bool
+		Value    model.Value
+		Color    model.Color
+		Copy     int
+	}
+
+	// ParseError wraps the reason input was rejected with the column it
+	// was found at.
+	ParseError struct {
+		Position int
+		Err      error
+	}
+)
+
+const (
+	// NumberArgument is a plain index such as a set, piece or position.
+	NumberArgument ArgumentKind = iota
+	// PieceArgument is a piece selection such as r0 (rack), p0 (loose pile)
+	// or s0 (set), or tile notation such as R7, B5.2 or J.
+	PieceArgument
+	// SetPieceArgument is a piece within a set, by index or tile notation.
+	SetPieceArgument
+	// PositionArgument is an index within a set, or start or end.
+	PositionArgument
+	// PiecesArgument is one or more piece selections and must come last.
+	PiecesArgument
+	// BoardArgument is piece IDs grouped by ';' with rack IDs after a '|'
+	// and must come last.
+	BoardArgument
+	// TextArgument is the rest of the input verbatim and must come last.
+	TextArgument
+)
+
+const (
+	setSeparator  = ';'
+	rackSeparator = '|'
+	idSeparator   = ','
+	copySeparator = '.'
+	startPosition = "start"
+	endPosition   = "end"
+)
+
+// Grammar defines the arguments of every command accepted from players.
+var Grammar = map[string][]Argument{
+	"combine":     {{"pieces", PiecesArgument}},
+	"insert":      {{"set", NumberArgument}, {"piece", PieceArgument}, {"position", PositionArgument}},
+	"remove":      {{"set", NumberArgument}, {"piece", SetPieceArgument}},
+	"split":       {{"set", NumberArgument}, {"index", NumberArgument}},
+	"move":        {{"from", NumberArgument}, {"piece", SetPieceArgument}, {"to", NumberArgument}, {"position", PositionArgument}},
+	"splitinsert": {{"set", NumberArgument}, {"piece", PieceArgument}, {"position", PositionArgument}},
+	"rearrange":   {{"board", BoardArgument}},
+	"name":        {{"name", TextArgument}},
+	"locale":      {{"locale", TextArgument}},
+	"undo":        {},
+	"end":         {},
+	"draw":        {},
+	"start":       {},
+	"abandon":     {},
+	"rules":       {{"hand", NumberArgument}, {"meld", NumberArgument}},
+	"swap":        {{"first", NumberArgument}, {"second", NumberArgument}},
+	"kick":        {{"player", TextArgument}},
+	"host":        {{"player", TextArgument}},
+	"pause":       {},
+	"resume":      {},
+}
+
+// TurnCommands play a turn, so may only be sent by the player whose turn it
+// is. The rest of the grammar sets up or runs a game hosted on a server,
+// apart from name.
+var TurnCommands = map[string]bool{
+	"combine":     true,
+	"insert":      true,
+	"remove":      true,
+	"move":        true,
+	"split":       true,
+	"splitinsert": true,
+	"rearrange":   true,
+	"undo":        true,
+	"end":         true,
+	"draw":        true,
+}
+
+func (e *ParseError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+func parseError(position int, message string) error {
+	return &ParseError{position, errors.New(message)}
+}
+
+// Commands returns the names of every command in the grammar, sorted.
+func Commands() []string {
+	names := make([]string, 0, len(Grammar))
+	for name := range Grammar {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Usage describes the arguments of a command, e.g. "insert <set> <piece> <position>".
+func Usage(name string) string {
+	usage := name
+	for _, argument := range Grammar[name] {
+		switch argument.Kind {
+		case PiecesArgument:
+			usage = usage + " <" + argument.Name + "...>"
+		default:
+			usage = usage + " <" + argument.Name + ">"
+		}
+	}
+	return usage
+}
+
+func isSeparator(r rune) bool {
+	return r == setSeparator || r == rackSeparator
+}
+
+// tokenize splits input on any run of whitespace or commas, keeping board
+// separators as tokens of their own.
+func tokenize(input string) []Token {
+	tokens := make([]Token, 0)
+	start := -1
+	for i, r := range input + " " {
+		split := unicode.IsSpace(r) || r == idSeparator || isSeparator(r)
+		if split && start >= 0 {
+			tokens = append(tokens, Token{Text: input[start:i], Position: start + 1})
+			start = -1
+		}
+		if isSeparator(r) {
+			tokens = append(tokens, Token{Text: string(r), Position: i + 1})
+		} else if !split && start < 0 {
+			start = i
+		}
+	}
+	return tokens
+}
+
+// isNotation reports whether a token is written in tile notation, which
+// always starts with an upper case letter, rather than as an index.
+func isNotation(token Token) bool {
+	return len(token.Text) > 0 && unicode.IsUpper(rune(token.Text[0]))
+}
+
+func parseNotationToken(token Token) (Token, error) {
+	notation, suffix, hasCopy := strings.Cut(token.Text, string(copySeparator))
+	value, color, err := model.ParseNotation(notation)
+	if err != nil {
+		return token, &ParseError{token.Position, err}
+	}
+	if hasCopy {
+		if suffix != "1" && suffix != "2" {
+			return token, parseError(token.Position+len(notation)+1, constants.InvalidPieceNotation)
+		}
+		token.Copy = int(suffix[0] - '0')
+	}
+	token.Notation, token.Value, token.Color = true, value, color
+	return token, nil
+}
+
+func parsePieceToken(token Token) (Token, error) {
+	if isNotation(token) {
+		return parseNotationToken(token)
+	}
+	if len(token.Text) < 2 {
+		return token, parseError(token.Position, constants.InvalidPieceSelection)
+	}
+	index, err := parseInt(token.Text[1:])
+	if err != nil {
+		return token, &ParseError{token.Position, err}
+	}
+	if !strings.ContainsRune("rps", rune(token.Text[0])) {
+		return token, parseError(token.Position, constants.InvalidPieceSelection)
+	}
+	token.Source, token.Number = token.Text[0], index
+	return token, nil
+}
+
+func parseSetPieceToken(token Token) (Token, error) {
+	if isNotation(token) {
+		return parseNotationToken(token)
+	}
+	return parseNumberToken(token)
+}
+
+func parsePositionToken(token Token) (Token, error) {
+	switch token.Text {
+	case startPosition:
+		token.Number = 0
+		return token, nil
+	case endPosition:
+		token.Number = -1
+		return token, nil
+	}
+	return parseNumberToken(token)
+}
+
+func parseNumberToken(token Token) (Token, error) {
+	number, err := parseInt(token.Text)
+	if err != nil {
+		return token, &ParseError{token.Position, err}
+	}
+	token.Number = number
+	return token, nil
+}
+
+// Parse checks input against the grammar of the named command and returns
+// one parsed token per argument, or every remaining token for arguments
+// that must come last.
+func Parse(name, input string) ([]Token, error) {
+	arguments, ok := Grammar[name]
+	if !ok {
+		return nil, parseError(1, constants.InvalidCommand)
+	}
+	return parseArguments(arguments, input)
+}
+
+func parseArguments(arguments []Argument, input string) ([]Token, error) {
+	tokens := tokenize(input)
+	parsed := make([]Token, 0, len(tokens))
+	if len(tokens) < len(arguments) {
+		end := len(strings.TrimRightFunc(input, unicode.IsSpace)) + 1
+		return nil, parseError(end, constants.TooFewArguments)
+	}
+	for i, argument := range arguments {
+		token, err := tokens[i], error(nil)
+		switch argument.Kind {
+		case NumberArgument:
+			token, err = parseNumberToken(token)
+		case PieceArgument:
+			token, err = parsePieceToken(token)
+		case SetPieceArgument:
+			token, err = parseSetPieceToken(token)
+		case PositionArgument:
+			token, err = parsePositionToken(token)
+		case PiecesArgument:
+			for _, token := range tokens[i:] {
+				if token, err = parsePieceToken(token); err != nil {
+					return nil, err
+				}
+				parsed = append(parsed, token)
+			}
+			return parsed, nil
+		case BoardArgument:
+			for _, token := range tokens[i:] {
+				if !isSeparator(rune(token.Text[0])) {
+					if token, err = parseNumberToken(token); err != nil {
+						return nil, err
+					}
+				}
+				parsed = append(parsed, token)
+			}
+			return parsed, nil
+		case TextArgument:
+			token.Text = strings.TrimSpace(input[token.Position-1:])
+			return append(parsed, token), nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		parsed = append(parsed, token)
+	}
+	if len(tokens) > len(arguments) {
+		return nil, parseError(tokens[len(arguments)].Position, constants.TooManyArguments)
+	}
+	return parsed, nil
+}
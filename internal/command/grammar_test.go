@@ -0,0 +1,122 @@
+package command
+
+import (
+	"errors"
+	"lets-play-rummikub/internal/constants"
+	"lets-play-rummikub/internal/model"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenize(t *testing.T) {
+	t.Run("ShouldIgnoreExtraWhitespace", func(t *testing.T) {
+		tokens := tokenize("  0 \tr1   2 ")
+		assert.Equal(t, tokens, []Token{{Text: "0", Position: 3}, {Text: "r1", Position: 6}, {Text: "2", Position: 11}})
+	})
+	t.Run("ShouldSplitBoardSeparators", func(t *testing.T) {
+		tokens := tokenize("1,2 3;4|4")
+		assert.Equal(t, tokens, []Token{
+			{Text: "1", Position: 1}, {Text: "2", Position: 3}, {Text: "3", Position: 5}, {Text: ";", Position: 6},
+			{Text: "4", Position: 7}, {Text: "|", Position: 8}, {Text: "4", Position: 9},
+		})
+	})
+	t.Run("ShouldReturnNoTokensOnEmptyInput", func(t *testing.T) {
+		assert.Empty(t, tokenize(""))
+		assert.Empty(t, tokenize("   "))
+	})
+}
+
+func TestParse(t *testing.T) {
+	t.Run("ShouldParseArguments", func(t *testing.T) {
+		tokens, err := Parse("insert", "1  p2 3")
+		assert.NoError(t, err)
+		assert.Len(t, tokens, 3)
+		assert.Equal(t, tokens[0].Number, 1)
+		assert.Equal(t, tokens[1].Source, byte('p'))
+		assert.Equal(t, tokens[1].Number, 2)
+		assert.Equal(t, tokens[2].Number, 3)
+	})
+	t.Run("ShouldParseText", func(t *testing.T) {
+		tokens, err := Parse("name", "  Ana  Maria ")
+		assert.NoError(t, err)
+		assert.Equal(t, tokens[0].Text, "Ana  Maria")
+	})
+	t.Run("ShouldReturnErrorOnUnknownCommand", func(t *testing.T) {
+		tokens, err := Parse("unknown", "")
+		assert.EqualError(t, err, constants.InvalidCommand)
+		assert.Nil(t, tokens)
+	})
+	t.Run("ShouldReturnPositionOnTooFewArguments", func(t *testing.T) {
+		_, err := Parse("split", "0 ")
+		var parseErr *ParseError
+		assert.True(t, errors.As(err, &parseErr))
+		assert.EqualError(t, parseErr.Err, constants.TooFewArguments)
+		assert.Equal(t, parseErr.Position, 2)
+	})
+	t.Run("ShouldReturnPositionOnTooManyArguments", func(t *testing.T) {
+		_, err := Parse("split", "0 1 2")
+		var parseErr *ParseError
+		assert.True(t, errors.As(err, &parseErr))
+		assert.EqualError(t, parseErr.Err, constants.TooManyArguments)
+		assert.Equal(t, parseErr.Position, 5)
+	})
+	t.Run("ShouldReturnPositionOnBadNumber", func(t *testing.T) {
+		_, err := Parse("insert", "0 r0 x")
+		var parseErr *ParseError
+		assert.True(t, errors.As(err, &parseErr))
+		assert.EqualError(t, parseErr.Err, constants.InvalidNumberInput)
+		assert.Equal(t, parseErr.Position, 6)
+	})
+	t.Run("ShouldReturnErrorOnShortPieceSelection", func(t *testing.T) {
+		_, err := Parse("combine", "r")
+		assert.EqualError(t, err, constants.InvalidPieceSelection)
+	})
+	t.Run("ShouldReturnErrorOnEmptyInput", func(t *testing.T) {
+		_, err := Parse("combine", "")
+		assert.EqualError(t, err, constants.TooFewArguments)
+	})
+}
+
+func TestUsage(t *testing.T) {
+	assert.Equal(t, Usage("insert"), "insert <set> <piece> <position>")
+	assert.Equal(t, Usage("combine"), "combine <pieces...>")
+	assert.Equal(t, Usage("undo"), "undo")
+	assert.Contains(t, Commands(), "move")
+}
+
+func TestTurnCommands(t *testing.T) {
+	for name := range TurnCommands {
+		assert.Contains(t, Grammar, name)
+	}
+	assert.False(t, TurnCommands["start"])
+}
+
+func TestParseNotation(t *testing.T) {
+	t.Run("ShouldParseTileNotation", func(t *testing.T) {
+		tokens, err := Parse("insert", "2 R7.2 end")
+		assert.NoError(t, err)
+		assert.True(t, tokens[1].Notation)
+		assert.Equal(t, tokens[1].Value, model.Value(7))
+		assert.Equal(t, tokens[1].Color, model.ColorRed)
+		assert.Equal(t, tokens[1].Copy, 2)
+		assert.Equal(t, tokens[2].Text, "end")
+	})
+	t.Run("ShouldParseJoker", func(t *testing.T) {
+		tokens, err := Parse("combine", "B5 B6 J")
+		assert.NoError(t, err)
+		assert.Len(t, tokens, 3)
+		assert.Equal(t, tokens[2].Value, model.ValueJoker)
+	})
+	t.Run("ShouldReturnPositionOnBadCopy", func(t *testing.T) {
+		_, err := Parse("combine", "R7.3")
+		var parseErr *ParseError
+		assert.True(t, errors.As(err, &parseErr))
+		assert.EqualError(t, parseErr.Err, constants.InvalidPieceNotation)
+		assert.Equal(t, parseErr.Position, 4)
+	})
+	t.Run("ShouldReturnErrorOnBadNotation", func(t *testing.T) {
+		_, err := Parse("remove", "0 R14")
+		assert.EqualError(t, err, constants.InvalidPieceNotation)
+	})
+}
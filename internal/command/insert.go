@@ -1,11 +1,6 @@
 package command
 
-import (
-	"errors"
-	"lets-play-rummikub/internal/constants"
-	"lets-play-rummikub/internal/model"
-	"strings"
-)
+import "lets-play-rummikub/internal/model"
 
 type insert struct {
 	player     model.Player
@@ -18,36 +13,19 @@ type insert struct {
 }
 
 func Insert(player model.Player, game model.Game, input string) (Command, error) {
-	selections := strings.Split(input, " ")
-	if len(selections) != 3 {
-		return nil, errors.New(constants.TooFewArguments)
-	}
-	setSelection, pieceSelection, position := selections[0], selections[1], selections[2]
-	index, err := parseInt(position)
+	tokens, err := Parse("insert", input)
 	if err != nil {
 		return nil, err
 	}
-	setIndex, err := parseInt(setSelection)
+	set, err := selectSet(game, tokens[0])
 	if err != nil {
 		return nil, err
 	}
-	pieceIndex, err := parseInt(pieceSelection[1:])
+	piece, err := selectPiece(tokens[1], game, nil, player, game)
 	if err != nil {
 		return nil, err
 	}
-	selectPiece, err := getPieceFrom(pieceSelection[0], player, game)
-	if err != nil {
-		return nil, err
-	}
-	set, err := game.Set(setIndex)
-	if err != nil {
-		return nil, errors.New(constants.InvalidSetSelection)
-	}
-	piece, err := selectPiece.Piece(pieceIndex)
-	if err != nil {
-		return nil, errors.New(constants.InvalidPieceSelection)
-	}
-	return &insert{player, game, set, piece, index, nil, nil}, nil
+	return &insert{player, game, set, piece, selectPosition(set, tokens[2]), nil, nil}, nil
 }
 
 func (i *insert) Undo() {
@@ -56,15 +34,16 @@ func (i *insert) Undo() {
 	i.game.Notify()
 }
 
-func (i *insert) Invoke() {
-	if insert, err := i.set.Insert(i.piece, i.index); err != nil {
-		i.game.Notify(err.Error())
-	} else {
-		i.undoGame = i.game.Clone()
-		i.undoPlayer = i.player.Clone()
-		i.player.RemovePiece(i.piece)
-		i.game.RemovePieces(i.piece)
-		i.game.ReplaceSet(i.set, insert)
-		i.game.Notify()
+func (i *insert) Invoke() error {
+	insert, err := i.set.Insert(i.piece, i.index)
+	if err != nil {
+		return err
 	}
+	i.undoGame = i.game.Clone()
+	i.undoPlayer = i.player.Clone()
+	i.player.RemovePiece(i.piece)
+	i.game.RemovePieces(i.piece)
+	i.game.ReplaceSet(i.set, insert)
+	i.game.Notify()
+	return nil
 }
@@ -155,3 +155,20 @@ func TestUndoInsert(t *testing.T) {
 		assert.Len(t, playerState["rack"], 0)
 	})
 }
+
+func TestInsertByNotation(t *testing.T) {
+	game := model.NewGame(1)
+	player := game.CurrentPlayer()
+	piece := model.NewPiece(model.Value(5), model.ColorBlack)
+	set := model.Combine(model.NewPiece(model.Value(2), model.ColorBlack), model.NewPiece(model.Value(3), model.ColorBlack), model.NewPiece(model.Value(4), model.ColorBlack))
+	dealPieces(player, piece)
+	setBoard(game, set)
+	command, err := Insert(player, game, "0 K5 end")
+	assert.NoError(t, err)
+	result := command.(*insert)
+	assert.Same(t, result.piece, piece)
+	assert.Equal(t, result.index, 3)
+	command.Invoke()
+	assert.True(t, game.IsValidBoard())
+	assert.Equal(t, player.RackLen(), 0)
+}
@@ -0,0 +1,61 @@
+package command
+
+import "lets-play-rummikub/internal/model"
+
+type move struct {
+	game     model.Game
+	from     model.Set
+	piece    model.Piece
+	to       model.Set
+	index    int
+	undoGame model.Game
+}
+
+func Move(game model.Game, input string) (Command, error) {
+	tokens, err := Parse("move", input)
+	if err != nil {
+		return nil, err
+	}
+	from, err := selectSet(game, tokens[0])
+	if err != nil {
+		return nil, err
+	}
+	piece, err := selectSetPiece(game, from, tokens[1])
+	if err != nil {
+		return nil, err
+	}
+	to, err := selectSet(game, tokens[2])
+	if err != nil {
+		return nil, err
+	}
+	return &move{game, from, piece, to, selectPosition(to, tokens[3]), nil}, nil
+}
+
+func (m *move) Undo() {
+	m.game.Restore(m.undoGame)
+	m.game.Notify()
+}
+
+func (m *move) Invoke() error {
+	removed, err := m.from.Remove(m.piece)
+	if err != nil {
+		return err
+	}
+	target := m.to
+	if m.from == m.to {
+		target = removed
+	}
+	inserted, err := target.Insert(m.piece, m.index)
+	if err != nil {
+		return err
+	}
+	m.undoGame = m.game.Clone()
+	if m.from == m.to {
+		m.game.ReplaceSet(m.from, inserted)
+	} else {
+		m.game.ReplaceSet(m.to, inserted)
+		m.game.ReplaceSet(m.from, removed)
+	}
+	m.game.Notify()
+	return nil
+}
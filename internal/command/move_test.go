@@ -0,0 +1,110 @@
+package command
+
+import (
+	"lets-play-rummikub/internal/constants"
+	"lets-play-rummikub/internal/model"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMove(t *testing.T) {
+	game := model.NewGame(1)
+	piece := model.NewPiece(model.Value(5), model.ColorBlack)
+	from := model.Combine(model.NewPiece(model.Value(2), model.ColorBlack), model.NewPiece(model.Value(3), model.ColorBlack), model.NewPiece(model.Value(4), model.ColorBlack), piece)
+	to := model.Combine(model.NewPiece(model.Value(6), model.ColorBlack), model.NewPiece(model.Value(7), model.ColorBlack), model.NewPiece(model.Value(8), model.ColorBlack))
+	setBoard(game, from, to)
+	t.Run("ShouldReturnMove", func(t *testing.T) {
+		command, err := Move(game, "0 3 1 0")
+		assert.NoError(t, err)
+		assert.NotNil(t, command)
+		result := command.(*move)
+		assert.Same(t, result.game, game)
+		assert.Same(t, result.from, from)
+		assert.Same(t, result.piece, piece)
+		assert.Same(t, result.to, to)
+		assert.Equal(t, result.index, 0)
+	})
+	t.Run("ShouldReturnErrorOnTooFewArguments", func(t *testing.T) {
+		command, err := Move(game, "0 3 1")
+		assert.EqualError(t, err, constants.TooFewArguments)
+		assert.Nil(t, command)
+	})
+	t.Run("ShouldReturnErrorOnBadSet", func(t *testing.T) {
+		command, err := Move(game, "bad 3 1 0")
+		assert.EqualError(t, err, constants.InvalidNumberInput)
+		assert.Nil(t, command)
+	})
+	t.Run("ShouldReturnErrorOnInvalidSet", func(t *testing.T) {
+		command, err := Move(game, "0 3 2 0")
+		assert.EqualError(t, err, constants.InvalidSetSelection)
+		assert.Nil(t, command)
+	})
+	t.Run("ShouldReturnErrorOnInvalidPiece", func(t *testing.T) {
+		command, err := Move(game, "0 4 1 0")
+		assert.EqualError(t, err, constants.InvalidPieceSelection)
+		assert.Nil(t, command)
+	})
+	t.Run("ShouldReturnErrorOnBadPosition", func(t *testing.T) {
+		command, err := Move(game, "0 3 1 bad")
+		assert.EqualError(t, err, constants.InvalidNumberInput)
+		assert.Nil(t, command)
+	})
+}
+
+func TestInvokeMove(t *testing.T) {
+	t.Run("ShouldMovePieceBetweenSets", func(t *testing.T) {
+		game := model.NewGame(1)
+		from := model.Combine(model.NewPiece(model.Value(2), model.ColorBlack), model.NewPiece(model.Value(3), model.ColorBlack), model.NewPiece(model.Value(4), model.ColorBlack), model.NewPiece(model.Value(5), model.ColorBlack))
+		to := model.Combine(model.NewPiece(model.Value(6), model.ColorBlack), model.NewPiece(model.Value(7), model.ColorBlack), model.NewPiece(model.Value(8), model.ColorBlack))
+		setBoard(game, from, to)
+		command, err := Move(game, "0 3 1 0")
+		assert.NoError(t, err)
+		command.Invoke()
+		gameState := unmarshal(t, game)
+		assert.Len(t, gameState["board"], 2)
+		assert.Len(t, gameState["board"].([]any)[0].(map[string]any)["pieces"], 3)
+		assert.Len(t, gameState["board"].([]any)[1].(map[string]any)["pieces"], 4)
+		assert.Equal(t, gameState["board"].([]any)[1].(map[string]any)["pieces"].([]any)[0].(map[string]any)["value"], float64(5))
+		assert.Len(t, gameState["piece"], 0)
+		assert.True(t, game.IsValidBoard())
+	})
+	t.Run("ShouldMovePieceWithinSet", func(t *testing.T) {
+		game := model.NewGame(1)
+		set := model.Combine(model.NewPiece(model.Value(3), model.ColorBlack), model.NewPiece(model.Value(2), model.ColorBlack), model.NewPiece(model.Value(4), model.ColorBlack))
+		setBoard(game, set)
+		command, err := Move(game, "0 0 0 1")
+		assert.NoError(t, err)
+		command.Invoke()
+		gameState := unmarshal(t, game)
+		assert.Len(t, gameState["board"], 1)
+		assert.True(t, game.IsValidBoard())
+	})
+	t.Run("ShouldDoNothingOnBadMove", func(t *testing.T) {
+		game := model.NewGame(1)
+		from := model.Combine(model.NewPiece(model.Value(2), model.ColorBlack), model.NewPiece(model.Value(3), model.ColorBlack), model.NewPiece(model.Value(4), model.ColorBlack))
+		to := model.Combine(model.NewPiece(model.Value(6), model.ColorBlack), model.NewPiece(model.Value(7), model.ColorBlack), model.NewPiece(model.Value(8), model.ColorBlack))
+		setBoard(game, from, to)
+		command, err := Move(game, "0 0 1 9")
+		assert.NoError(t, err)
+		assert.Error(t, command.Invoke())
+		gameState := unmarshal(t, game)
+		assert.Len(t, gameState["board"].([]any)[0].(map[string]any)["pieces"], 3)
+		assert.Len(t, gameState["board"].([]any)[1].(map[string]any)["pieces"], 3)
+	})
+}
+
+func TestUndoMove(t *testing.T) {
+	game := model.NewGame(1)
+	from := model.Combine(model.NewPiece(model.Value(2), model.ColorBlack), model.NewPiece(model.Value(3), model.ColorBlack), model.NewPiece(model.Value(4), model.ColorBlack), model.NewPiece(model.Value(5), model.ColorBlack))
+	to := model.Combine(model.NewPiece(model.Value(6), model.ColorBlack), model.NewPiece(model.Value(7), model.ColorBlack), model.NewPiece(model.Value(8), model.ColorBlack))
+	setBoard(game, from, to)
+	command, err := Move(game, "0 3 1 0")
+	assert.NoError(t, err)
+	command.Invoke()
+	command.Undo()
+	gameState := unmarshal(t, game)
+	assert.Len(t, gameState["board"], 2)
+	assert.Len(t, gameState["board"].([]any)[0].(map[string]any)["pieces"], 4)
+	assert.Len(t, gameState["board"].([]any)[1].(map[string]any)["pieces"], 3)
+}
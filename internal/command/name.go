@@ -15,6 +15,7 @@ func (*setName) Undo() {
 	// not undoable
 }
 
-func (n *setName) Invoke() {
+func (n *setName) Invoke() error {
 	n.player.SetName(n.name)
+	return nil
 }
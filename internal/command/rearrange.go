@@ -0,0 +1,128 @@
+package command
+
+import (
+	"errors"
+	"lets-play-rummikub/internal/constants"
+	"lets-play-rummikub/internal/model"
+)
+
+type rearrange struct {
+	player     model.Player
+	game       model.Game
+	sets       [][]model.Piece
+	rack       []model.Piece
+	undoGame   model.Game
+	undoPlayer model.Player
+}
+
+// Rearrange takes the full proposed board as piece IDs, sets separated by ';'
+// and the rack pieces used listed after a '|', e.g. "1 2 3;14 27 40|40".
+func Rearrange(player model.Player, game model.Game, input string) (Command, error) {
+	tokens, err := Parse("rearrange", input)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[model.Piece]bool)
+	sets, rack := make([][]model.Piece, 0), make([]model.Piece, 0)
+	pieces, inRack := make([]model.Piece, 0), false
+	for _, token := range tokens {
+		switch token.Text {
+		case string(setSeparator), string(rackSeparator):
+			if inRack {
+				return nil, parseError(token.Position, constants.InvalidSetSelection)
+			}
+			if len(pieces) > 0 {
+				sets = append(sets, pieces)
+			}
+			pieces, inRack = make([]model.Piece, 0), token.Text == string(rackSeparator)
+			continue
+		}
+		piece, err := game.PieceByID(token.Number)
+		if err != nil {
+			return nil, &ParseError{token.Position, err}
+		}
+		if seen[piece] && !inRack {
+			return nil, parseError(token.Position, constants.DuplicatePiece)
+		}
+		seen[piece] = true
+		if inRack {
+			rack = append(rack, piece)
+		} else {
+			pieces = append(pieces, piece)
+		}
+	}
+	if len(pieces) > 0 && !inRack {
+		sets = append(sets, pieces)
+	}
+	if len(sets) == 0 {
+		return nil, errors.New(constants.TooFewArguments)
+	}
+	return &rearrange{player, game, sets, rack, nil, nil}, nil
+}
+
+func rackContains(player model.Player, piece model.Piece) bool {
+	for i := 0; i < player.RackLen(); i++ {
+		if p, _ := player.Piece(i); p == piece {
+			return true
+		}
+	}
+	return false
+}
+
+// validate checks that the proposed board uses exactly the pieces on the
+// table plus the selected rack pieces and that every set is valid.
+func (r *rearrange) validate() ([]model.Set, error) {
+	used := make(map[model.Piece]bool)
+	for _, pieces := range r.sets {
+		for _, piece := range pieces {
+			used[piece] = true
+		}
+	}
+	available := make(map[model.Piece]bool)
+	for _, piece := range r.game.TablePieces() {
+		if !used[piece] {
+			return nil, errors.New(constants.TablePiecesMissing)
+		}
+		available[piece] = true
+	}
+	for _, piece := range r.rack {
+		if !rackContains(r.player, piece) {
+			return nil, errors.New(constants.PieceNotInRack)
+		}
+		available[piece] = true
+	}
+	if len(available) != len(used) {
+		return nil, errors.New(constants.RackPiecesMismatch)
+	}
+	for piece := range used {
+		if !available[piece] {
+			return nil, errors.New(constants.RackPiecesMismatch)
+		}
+	}
+	sets := make([]model.Set, len(r.sets))
+	for i, pieces := range r.sets {
+		sets[i] = model.Combine(pieces...)
+		if !sets[i].IsValidSet() {
+			return nil, errors.New(constants.InvalidSet)
+		}
+	}
+	return sets, nil
+}
+
+func (r *rearrange) Undo() {
+	r.game.Restore(r.undoGame)
+	r.player.Restore(r.undoPlayer)
+	r.game.Notify()
+}
+
+func (r *rearrange) Invoke() error {
+	sets, err := r.validate()
+	if err != nil {
+		return err
+	}
+	r.undoGame, r.undoPlayer = r.game.Clone(), r.player.Clone()
+	r.player.RemovePiece(r.rack...)
+	r.game.ReplaceBoard(sets...)
+	r.game.Notify()
+	return nil
+}
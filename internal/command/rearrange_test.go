@@ -0,0 +1,107 @@
+package command
+
+import (
+	"lets-play-rummikub/internal/constants"
+	"lets-play-rummikub/internal/model"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func piecesByID(t *testing.T, game model.Game, ids ...int) []model.Piece {
+	pieces := make([]model.Piece, len(ids))
+	for i, id := range ids {
+		piece, err := game.PieceByID(id)
+		assert.NoError(t, err)
+		pieces[i] = piece
+	}
+	return pieces
+}
+
+func setupRearrange(t *testing.T) (model.Game, model.Player) {
+	game := model.NewGame(1)
+	player := game.CurrentPlayer()
+	setBoard(game, model.Combine(piecesByID(t, game, 5, 6, 7)...))
+	dealPieces(player, piecesByID(t, game, 8, 66)...)
+	return game, player
+}
+
+func TestRearrange(t *testing.T) {
+	game, player := setupRearrange(t)
+	t.Run("ShouldReturnRearrange", func(t *testing.T) {
+		command, err := Rearrange(player, game, "5 6 7 8|8")
+		assert.NoError(t, err)
+		assert.NotNil(t, command)
+		result := command.(*rearrange)
+		assert.Same(t, result.game, game)
+		assert.Same(t, result.player, player)
+		assert.Equal(t, result.sets, [][]model.Piece{piecesByID(t, game, 5, 6, 7, 8)})
+		assert.Equal(t, result.rack, piecesByID(t, game, 8))
+	})
+	t.Run("ShouldReturnErrorOnEmptyBoard", func(t *testing.T) {
+		command, err := Rearrange(player, game, "|8")
+		assert.EqualError(t, err, constants.TooFewArguments)
+		assert.Nil(t, command)
+	})
+	t.Run("ShouldReturnErrorOnBadID", func(t *testing.T) {
+		command, err := Rearrange(player, game, "5 bad 7")
+		assert.EqualError(t, err, constants.InvalidNumberInput)
+		assert.Nil(t, command)
+	})
+	t.Run("ShouldReturnErrorOnUnknownID", func(t *testing.T) {
+		command, err := Rearrange(player, game, "5 6 107")
+		assert.EqualError(t, err, constants.InvalidPieceSelection)
+		assert.Nil(t, command)
+	})
+	t.Run("ShouldReturnErrorOnDuplicateID", func(t *testing.T) {
+		command, err := Rearrange(player, game, "5 6 7;5 6 7")
+		assert.EqualError(t, err, constants.DuplicatePiece)
+		assert.Nil(t, command)
+	})
+}
+
+func TestInvokeRearrange(t *testing.T) {
+	t.Run("ShouldReplaceBoard", func(t *testing.T) {
+		game, player := setupRearrange(t)
+		command, err := Rearrange(player, game, "5 6 7 8|8")
+		assert.NoError(t, err)
+		command.Invoke()
+		gameState := unmarshal(t, game)
+		assert.Len(t, gameState["board"], 1)
+		assert.Len(t, gameState["board"].([]any)[0].(map[string]any)["pieces"], 4)
+		assert.Equal(t, player.RackLen(), 1)
+	})
+	for _, test := range []struct {
+		name  string
+		input string
+	}{
+		{"ShouldDoNothingOnMissingTablePiece", "6 7 8|8"},
+		{"ShouldDoNothingOnPieceNotInRack", "5 6 7 9|9"},
+		{"ShouldDoNothingOnUnlistedRackPiece", "5 6 7 8"},
+		{"ShouldDoNothingOnUnusedRackPiece", "5 6 7|8"},
+		{"ShouldDoNothingOnInvalidSet", "5 6;7 8|8"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			game, player := setupRearrange(t)
+			command, err := Rearrange(player, game, test.input)
+			assert.NoError(t, err)
+			assert.Error(t, command.Invoke())
+			gameState := unmarshal(t, game)
+			assert.Len(t, gameState["board"], 1)
+			assert.Len(t, gameState["board"].([]any)[0].(map[string]any)["pieces"], 3)
+			assert.Equal(t, player.RackLen(), 2)
+		})
+	}
+}
+
+func TestUndoRearrange(t *testing.T) {
+	game, player := setupRearrange(t)
+	command, err := Rearrange(player, game, "5 6 7 8|8")
+	assert.NoError(t, err)
+	command.Invoke()
+	command.Undo()
+	gameState := unmarshal(t, game)
+	assert.Len(t, gameState["board"], 1)
+	assert.Len(t, gameState["board"].([]any)[0].(map[string]any)["pieces"], 3)
+	assert.Equal(t, player.RackLen(), 2)
+}
@@ -1,11 +1,6 @@
 package command
 
-import (
-	"errors"
-	"lets-play-rummikub/internal/constants"
-	"lets-play-rummikub/internal/model"
-	"strings"
-)
+import "lets-play-rummikub/internal/model"
 
 type remove struct {
 	game     model.Game
@@ -15,24 +10,15 @@ type remove struct {
 }
 
 func Remove(game model.Game, input string) (Command, error) {
-	selections := strings.Split(input, " ")
-	if len(selections) != 2 {
-		return nil, errors.New(constants.TooFewArguments)
-	}
-	setSelection, pieceSelection := selections[0], selections[1]
-	setIndex, err := parseInt(setSelection)
-	if err != nil {
-		return nil, err
-	}
-	set, err := game.Set(setIndex)
+	tokens, err := Parse("remove", input)
 	if err != nil {
 		return nil, err
 	}
-	pieceIndex, err := parseInt(pieceSelection)
+	set, err := selectSet(game, tokens[0])
 	if err != nil {
 		return nil, err
 	}
-	piece, err := set.Piece(pieceIndex)
+	piece, err := selectSetPiece(game, set, tokens[1])
 	if err != nil {
 		return nil, err
 	}
@@ -44,13 +30,14 @@ func (r *remove) Undo() {
 	r.game.Notify()
 }
 
-func (r *remove) Invoke() {
-	if remove, err := r.set.Remove(r.piece); err != nil {
-		r.game.Notify(err.Error())
-	} else {
-		r.undoGame = r.game.Clone()
-		r.game.AddLoosePiece(r.piece)
-		r.game.ReplaceSet(r.set, remove)
-		r.game.Notify()
+func (r *remove) Invoke() error {
+	remove, err := r.set.Remove(r.piece)
+	if err != nil {
+		return err
 	}
+	r.undoGame = r.game.Clone()
+	r.game.AddLoosePiece(r.piece)
+	r.game.ReplaceSet(r.set, remove)
+	r.game.Notify()
+	return nil
 }
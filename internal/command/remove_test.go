@@ -91,7 +91,7 @@ func TestInvokeRemove(t *testing.T) {
 		command, err := Remove(game, "0 0")
 		assert.NoError(t, err)
 		command.(*remove).piece = badPiece
-		command.Invoke()
+		assert.Error(t, command.Invoke())
 		gameState := unmarshal(t, game)
 		assert.Len(t, gameState["board"], 1)
 		assert.Len(t, gameState["board"].([]any)[0].(map[string]any)["pieces"], 4)
@@ -113,3 +113,16 @@ func TestUndoRemove(t *testing.T) {
 	assert.Len(t, gameState["board"].([]any)[0].(map[string]any)["pieces"], 4)
 	assert.Len(t, gameState["piece"], 0)
 }
+
+func TestRemoveByNotation(t *testing.T) {
+	game := model.NewGame(1)
+	piece := model.NewPiece(model.Value(4), model.ColorBlack)
+	set := model.Combine(model.NewPiece(model.Value(1), model.ColorBlack), model.NewPiece(model.Value(2), model.ColorBlack), model.NewPiece(model.Value(3), model.ColorBlack), piece)
+	setBoard(game, set)
+	command, err := Remove(game, "0 K4")
+	assert.NoError(t, err)
+	assert.Same(t, command.(*remove).piece, piece)
+	command, err = Remove(game, "0 R4")
+	assert.EqualError(t, err, constants.InvalidPieceSelection)
+	assert.Nil(t, command)
+}
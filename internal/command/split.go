@@ -1,11 +1,6 @@
 package command
 
-import (
-	"errors"
-	"lets-play-rummikub/internal/constants"
-	"lets-play-rummikub/internal/model"
-	"strings"
-)
+import "lets-play-rummikub/internal/model"
 
 type split struct {
 	game     model.Game
@@ -15,24 +10,15 @@ type split struct {
 }
 
 func Split(game model.Game, input string) (Command, error) {
-	selections := strings.Split(input, " ")
-	if len(selections) != 2 {
-		return nil, errors.New(constants.TooFewArguments)
-	}
-	setSelection, splitIndex := selections[0], selections[1]
-	setIndex, err := parseInt(setSelection)
-	if err != nil {
-		return nil, err
-	}
-	set, err := game.Set(setIndex)
+	tokens, err := Parse("split", input)
 	if err != nil {
 		return nil, err
 	}
-	index, err := parseInt(splitIndex)
+	set, err := selectSet(game, tokens[0])
 	if err != nil {
 		return nil, err
 	}
-	return &split{game, set, index, nil}, nil
+	return &split{game, set, tokens[1].Number, nil}, nil
 }
 
 func (s *split) Undo() {
@@ -40,13 +26,14 @@ func (s *split) Undo() {
 	s.game.Notify()
 }
 
-func (s *split) Invoke() {
-	if lowerSet, upperSet, err := s.set.Split(s.index); err != nil {
-		s.game.Notify(err.Error())
-	} else {
-		s.undoGame = s.game.Clone()
-		s.game.ReplaceSet(s.set, lowerSet)
-		s.game.AddSet(upperSet)
-		s.game.Notify()
+func (s *split) Invoke() error {
+	lowerSet, upperSet, err := s.set.Split(s.index)
+	if err != nil {
+		return err
 	}
+	s.undoGame = s.game.Clone()
+	s.game.ReplaceSet(s.set, lowerSet)
+	s.game.AddSet(upperSet)
+	s.game.Notify()
+	return nil
 }
@@ -0,0 +1,69 @@
+package command
+
+import (
+	"errors"
+	"lets-play-rummikub/internal/constants"
+	"lets-play-rummikub/internal/model"
+)
+
+type splitInsert struct {
+	player     model.Player
+	game       model.Game
+	set        model.Set
+	piece      model.Piece
+	index      int
+	undoGame   model.Game
+	undoPlayer model.Player
+}
+
+func SplitInsert(player model.Player, game model.Game, input string) (Command, error) {
+	tokens, err := Parse("splitinsert", input)
+	if err != nil {
+		return nil, err
+	}
+	set, err := selectSet(game, tokens[0])
+	if err != nil {
+		return nil, err
+	}
+	piece, err := selectPiece(tokens[1], game, nil, player, game)
+	if err != nil {
+		return nil, err
+	}
+	return &splitInsert{player, game, set, piece, selectPosition(set, tokens[2]), nil, nil}, nil
+}
+
+func (s *splitInsert) Undo() {
+	s.game.Restore(s.undoGame)
+	s.player.Restore(s.undoPlayer)
+	s.game.Notify()
+}
+
+// splitAround splits the set so that the piece at index either starts the
+// upper set or ends the lower set, whichever leaves two valid sets.
+func splitAround(set model.Set, index int) (model.Set, model.Set, error) {
+	for _, at := range []int{index, index + 1} {
+		lower, upper, err := set.Split(at)
+		if err == nil && lower.IsValidSet() && upper.IsValidSet() {
+			return lower, upper, nil
+		}
+	}
+	return nil, nil, errors.New(constants.CannotSplit)
+}
+
+func (s *splitInsert) Invoke() error {
+	inserted, err := s.set.Insert(s.piece, s.index)
+	if err != nil {
+		return err
+	}
+	lower, upper, err := splitAround(inserted, s.index)
+	if err != nil {
+		return err
+	}
+	s.undoGame, s.undoPlayer = s.game.Clone(), s.player.Clone()
+	s.player.RemovePiece(s.piece)
+	s.game.RemovePieces(s.piece)
+	s.game.ReplaceSet(s.set, lower)
+	s.game.AddSet(upper)
+	s.game.Notify()
+	return nil
+}
@@ -0,0 +1,116 @@
+package command
+
+import (
+	"lets-play-rummikub/internal/constants"
+	"lets-play-rummikub/internal/model"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func createRun(color model.Color, from, to int) model.Set {
+	pieces := make([]model.Piece, 0)
+	for value := from; value <= to; value++ {
+		pieces = append(pieces, model.NewPiece(model.Value(value), color))
+	}
+	return model.Combine(pieces...)
+}
+
+func TestSplitInsert(t *testing.T) {
+	game := model.NewGame(1)
+	player := game.CurrentPlayer()
+	piece := model.NewPiece(model.Value(6), model.ColorRed)
+	set := createRun(model.ColorRed, 3, 8)
+	dealPieces(player, piece)
+	setBoard(game, set)
+	t.Run("ShouldReturnSplitInsert", func(t *testing.T) {
+		command, err := SplitInsert(player, game, "0 r0 3")
+		assert.NoError(t, err)
+		assert.NotNil(t, command)
+		result := command.(*splitInsert)
+		assert.Same(t, result.game, game)
+		assert.Same(t, result.player, player)
+		assert.Same(t, result.set, set)
+		assert.Same(t, result.piece, piece)
+		assert.Equal(t, result.index, 3)
+	})
+	t.Run("ShouldReturnErrorOnTooFewArguments", func(t *testing.T) {
+		command, err := SplitInsert(player, game, "0 r0")
+		assert.EqualError(t, err, constants.TooFewArguments)
+		assert.Nil(t, command)
+	})
+	t.Run("ShouldReturnErrorOnInvalidSet", func(t *testing.T) {
+		command, err := SplitInsert(player, game, "1 r0 3")
+		assert.EqualError(t, err, constants.InvalidSetSelection)
+		assert.Nil(t, command)
+	})
+	t.Run("ShouldReturnErrorOnBadPieceSelection", func(t *testing.T) {
+		command, err := SplitInsert(player, game, "0 s0 3")
+		assert.EqualError(t, err, constants.InvalidPieceSelection)
+		assert.Nil(t, command)
+	})
+	t.Run("ShouldReturnErrorOnBadPosition", func(t *testing.T) {
+		command, err := SplitInsert(player, game, "0 r0 bad")
+		assert.EqualError(t, err, constants.InvalidNumberInput)
+		assert.Nil(t, command)
+	})
+}
+
+func TestInvokeSplitInsert(t *testing.T) {
+	t.Run("ShouldInsertAndSplitRun", func(t *testing.T) {
+		game := model.NewGame(1)
+		player := game.CurrentPlayer()
+		dealPieces(player, model.NewPiece(model.Value(6), model.ColorRed))
+		setBoard(game, createRun(model.ColorRed, 3, 8))
+		command, err := SplitInsert(player, game, "0 r0 3")
+		assert.NoError(t, err)
+		command.Invoke()
+		gameState := unmarshal(t, game)
+		assert.Len(t, gameState["board"], 2)
+		assert.Len(t, gameState["board"].([]any)[0].(map[string]any)["pieces"], 4)
+		assert.Len(t, gameState["board"].([]any)[1].(map[string]any)["pieces"], 3)
+		assert.True(t, game.IsValidBoard())
+		assert.Equal(t, player.RackLen(), 0)
+	})
+	t.Run("ShouldInsertAfterMatchingPiece", func(t *testing.T) {
+		game := model.NewGame(1)
+		player := game.CurrentPlayer()
+		dealPieces(player, model.NewPiece(model.Value(5), model.ColorRed))
+		setBoard(game, createRun(model.ColorRed, 3, 7))
+		command, err := SplitInsert(player, game, "0 r0 3")
+		assert.NoError(t, err)
+		command.Invoke()
+		gameState := unmarshal(t, game)
+		assert.Len(t, gameState["board"], 2)
+		assert.True(t, game.IsValidBoard())
+		assert.Equal(t, player.RackLen(), 0)
+	})
+	t.Run("ShouldDoNothingOnInvalidSplit", func(t *testing.T) {
+		game := model.NewGame(1)
+		player := game.CurrentPlayer()
+		dealPieces(player, model.NewPiece(model.Value(4), model.ColorRed))
+		setBoard(game, createRun(model.ColorRed, 3, 6))
+		command, err := SplitInsert(player, game, "0 r0 1")
+		assert.NoError(t, err)
+		assert.Error(t, command.Invoke())
+		gameState := unmarshal(t, game)
+		assert.Len(t, gameState["board"], 1)
+		assert.Len(t, gameState["board"].([]any)[0].(map[string]any)["pieces"], 4)
+		assert.Equal(t, player.RackLen(), 1)
+	})
+}
+
+func TestUndoSplitInsert(t *testing.T) {
+	game := model.NewGame(1)
+	player := game.CurrentPlayer()
+	dealPieces(player, model.NewPiece(model.Value(6), model.ColorRed))
+	setBoard(game, createRun(model.ColorRed, 3, 8))
+	command, err := SplitInsert(player, game, "0 r0 3")
+	assert.NoError(t, err)
+	command.Invoke()
+	command.Undo()
+	gameState := unmarshal(t, game)
+	assert.Len(t, gameState["board"], 1)
+	assert.Len(t, gameState["board"].([]any)[0].(map[string]any)["pieces"], 6)
+	assert.Equal(t, player.RackLen(), 1)
+}
@@ -77,7 +77,7 @@ func TestInvokeSplit(t *testing.T) {
 		command, err := Split(game, "0 0")
 		assert.NoError(t, err)
 		assert.NotNil(t, command)
-		command.Invoke()
+		assert.Error(t, command.Invoke())
 		gameState := unmarshal(t, game)
 		assert.Len(t, gameState["board"], 1)
 		assert.Len(t, gameState["board"].([]any)[0].(map[string]any)["pieces"], 4)
@@ -0,0 +1,250 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"lets-play-rummikub/internal/constants"
+	"lets-play-rummikub/internal/model"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// envPrefix is prepended to every environment variable, e.g. RUMMIKUB_ADDR.
+const envPrefix = "RUMMIKUB_"
+
+type (
+	// Config is read from defaults, then a JSON config file, then
+	// environment variables and finally command line flags, each
+	// overriding the last.
+	Config struct {
+		Addr           string      `json:"addr"`
+		Template       string      `json:"template"`
+		AllowedOrigins []string    `json:"allowedOrigins"`
+		RoomSize       uint        `json:"roomSize"`
+		StoragePath    string      `json:"storagePath"`
+		Rules          model.Rules `json:"rules"`
+		Timers         Timers      `json:"timers"`
+		Limits         Limits      `json:"limits"`
+		Log            Log         `json:"log"`
+	}
+
+	Timers struct {
+		WriteWait       Duration `json:"writeWait"`
+		PongWait        Duration `json:"pongWait"`
+		ShutdownTimeout Duration `json:"shutdownTimeout"`
+		MinRevealDelay  Duration `json:"minRevealDelay"`
+	}
+
+	Limits struct {
+		MaxMessageSize int64   `json:"maxMessageSize"`
+		SendQueue      int     `json:"sendQueue"`
+		RateLimit      float64 `json:"rateLimit"`
+		RateBurst      int     `json:"rateBurst"`
+	}
+
+	Log struct {
+		Level  string `json:"level"`
+		Format string `json:"format"`
+	}
+
+	// Duration is a time.Duration written as a string such as "10s" in
+	// config files.
+	Duration time.Duration
+
+	option struct {
+		name  string
+		usage string
+		set   func(*Config, string) error
+	}
+)
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return err
+	}
+	parsed, err := time.ParseDuration(text)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// Default returns the settings the server used before it was configurable.
+func Default() Config {
+	return Config{
+		Addr:     ":8080",
+		Template: "template/home.html",
+		RoomSize: 2,
+		Rules:    model.DefaultRules(),
+		Timers: Timers{
+			WriteWait:       Duration(10 * time.Second),
+			PongWait:        Duration(60 * time.Second),
+			ShutdownTimeout: Duration(10 * time.Second),
+			MinRevealDelay:  Duration(2 * time.Minute),
+		},
+		Limits: Limits{MaxMessageSize: 512, SendQueue: 64, RateLimit: 10, RateBurst: 20},
+		Log:    Log{Level: "info", Format: "text"},
+	}
+}
+
+func setString(field func(*Config) *string) func(*Config, string) error {
+	return func(c *Config, value string) error {
+		*field(c) = value
+		return nil
+	}
+}
+
+func setInt(field func(*Config) *int) func(*Config, string) error {
+	return func(c *Config, value string) error {
+		parsed, err := strconv.Atoi(value)
+		*field(c) = parsed
+		return err
+	}
+}
+
+func setDuration(field func(*Config) *Duration) func(*Config, string) error {
+	return func(c *Config, value string) error {
+		parsed, err := time.ParseDuration(value)
+		*field(c) = Duration(parsed)
+		return err
+	}
+}
+
+var options = []option{
+	{"addr", "address to listen on", setString(func(c *Config) *string { return &c.Addr })},
+	{"template", "path of the page served at /", setString(func(c *Config) *string { return &c.Template })},
+	{"allowed-origins", "comma separated origins browsers may connect from, or * for any", func(c *Config, value string) error {
+		c.AllowedOrigins = strings.Split(value, ",")
+		for i, origin := range c.AllowedOrigins {
+			c.AllowedOrigins[i] = strings.TrimSpace(origin)
+		}
+		return nil
+	}},
+	{"room-size", "seats in rooms created without a size", func(c *Config, value string) error {
+		parsed, err := strconv.ParseUint(value, 10, 8)
+		c.RoomSize = uint(parsed)
+		return err
+	}},
+	{"storage", "directory games are saved to on shutdown, or empty to not save", setString(func(c *Config) *string { return &c.StoragePath })},
+	{"hand-size", "pieces dealt to each player", setInt(func(c *Config) *int { return &c.Rules.HandSize })},
+	{"initial-meld", "points a player's first meld must be worth", setInt(func(c *Config) *int { return &c.Rules.InitialMeld })},
+	{"write-wait", "time allowed to write a message to a client", setDuration(func(c *Config) *Duration { return &c.Timers.WriteWait })},
+	{"pong-wait", "time allowed between pongs before a client is dropped", setDuration(func(c *Config) *Duration { return &c.Timers.PongWait })},
+	{"shutdown-timeout", "time allowed to save games and close connections", setDuration(func(c *Config) *Duration { return &c.Timers.ShutdownTimeout })},
+	{"min-reveal-delay", "shortest spectator delay a room may reveal racks with, at least a full turn", setDuration(func(c *Config) *Duration { return &c.Timers.MinRevealDelay })},
+	{"max-message-size", "largest message accepted from a client, in bytes", func(c *Config, value string) error {
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		c.Limits.MaxMessageSize = parsed
+		return err
+	}},
+	{"send-queue", "messages queued for a client before it is disconnected", setInt(func(c *Config) *int { return &c.Limits.SendQueue })},
+	{"rate-limit", "messages per second a client may send on average", func(c *Config, value string) error {
+		parsed, err := strconv.ParseFloat(value, 64)
+		c.Limits.RateLimit = parsed
+		return err
+	}},
+	{"rate-burst", "messages a client may send at once before being limited", setInt(func(c *Config) *int { return &c.Limits.RateBurst })},
+	{"log-level", "debug, info, warn or error", setString(func(c *Config) *string { return &c.Log.Level })},
+	{"log-format", "text or json", setString(func(c *Config) *string { return &c.Log.Format })},
+}
+
+// envName returns the environment variable for a flag, e.g. RUMMIKUB_ROOM_SIZE.
+func envName(name string) string {
+	env := []byte(envPrefix + name)
+	for i, b := range env {
+		switch {
+		case b == '-':
+			env[i] = '_'
+		case b >= 'a' && b <= 'z':
+			env[i] = b - 'a' + 'A'
+		}
+	}
+	return string(env)
+}
+
+// Load reads the configuration from a config file named by the -config
+// flag or RUMMIKUB_CONFIG, then the environment, then flags in args.
+func Load(args []string, getenv func(string) string) (Config, error) {
+	flags := flag.NewFlagSet("rummikub", flag.ContinueOnError)
+	path := flags.String("config", getenv(envPrefix+"CONFIG"), "JSON config file")
+	set := make([]func(*Config) error, 0)
+	for _, opt := range options {
+		flags.Func(opt.name, fmt.Sprintf("%s (env %s)", opt.usage, envName(opt.name)), func(value string) error {
+			set = append(set, func(c *Config) error { return opt.set(c, value) })
+			return nil
+		})
+	}
+	if err := flags.Parse(args); err != nil {
+		return Config{}, err
+	}
+
+	config := Default()
+	if *path != "" {
+		file, err := os.Open(*path)
+		if err != nil {
+			return Config{}, err
+		}
+		defer file.Close()
+		decoder := json.NewDecoder(file)
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&config); err != nil {
+			return Config{}, fmt.Errorf(constants.InvalidConfigFile, *path, err)
+		}
+	}
+	for _, opt := range options {
+		if value := getenv(envName(opt.name)); value != "" {
+			if err := opt.set(&config, value); err != nil {
+				return Config{}, fmt.Errorf(constants.InvalidConfigValue, envName(opt.name), value)
+			}
+		}
+	}
+	for _, apply := range set {
+		if err := apply(&config); err != nil {
+			return Config{}, err
+		}
+	}
+	return config, config.Validate()
+}
+
+// Validate checks the settings can be used to run a server.
+func (c Config) Validate() error {
+	if c.RoomSize < 1 || c.RoomSize > 4 {
+		return errors.New(constants.InvalidSeatCount)
+	}
+	if err := c.Rules.Validate(int(c.RoomSize)); err != nil {
+		return err
+	}
+	if c.Timers.WriteWait <= 0 || c.Timers.PongWait <= 0 || c.Timers.ShutdownTimeout <= 0 || c.Timers.MinRevealDelay <= 0 {
+		return errors.New(constants.InvalidTimer)
+	}
+	if c.Limits.MaxMessageSize < 1 || c.Limits.SendQueue < 1 || c.Limits.RateLimit <= 0 || c.Limits.RateBurst < 1 {
+		return errors.New(constants.InvalidLimit)
+	}
+	if _, err := c.Log.SlogLevel(); err != nil {
+		return err
+	}
+	if c.Log.Format != "text" && c.Log.Format != "json" {
+		return fmt.Errorf(constants.InvalidConfigValue, "log format", c.Log.Format)
+	}
+	return nil
+}
+
+// SlogLevel parses Level as a slog level name.
+func (l Log) SlogLevel() (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(l.Level)); err != nil {
+		return level, fmt.Errorf(constants.InvalidConfigValue, "log level", l.Level)
+	}
+	return level, nil
+}
@@ -0,0 +1,78 @@
+package config
+
+import (
+	"fmt"
+	"lets-play-rummikub/internal/constants"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func env(values map[string]string) func(string) string {
+	return func(name string) string { return values[name] }
+}
+
+func writeConfig(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "config.json")
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("ShouldUseDefaults", func(t *testing.T) {
+		config, err := Load(nil, env(nil))
+		assert.NoError(t, err)
+		assert.Equal(t, config, Default())
+	})
+	t.Run("ShouldOverrideFileWithEnvAndFlags", func(t *testing.T) {
+		path := writeConfig(t, `{"addr": ":9000", "roomSize": 3, "rules": {"handSize": 10, "initialMeld": 25}, "timers": {"pongWait": "30s"}}`)
+		config, err := Load([]string{"-config", path, "-room-size", "4"}, env(map[string]string{
+			"RUMMIKUB_ROOM_SIZE":    "1",
+			"RUMMIKUB_INITIAL_MELD": "20",
+			"RUMMIKUB_LOG_FORMAT":   "json",
+		}))
+		assert.NoError(t, err)
+		assert.Equal(t, config.Addr, ":9000")
+		assert.Equal(t, config.RoomSize, uint(4))
+		assert.Equal(t, config.Rules.HandSize, 10)
+		assert.Equal(t, config.Rules.InitialMeld, 20)
+		assert.Equal(t, time.Duration(config.Timers.PongWait), 30*time.Second)
+		assert.Equal(t, time.Duration(config.Timers.WriteWait), 10*time.Second)
+		assert.Equal(t, config.Log.Format, "json")
+	})
+	t.Run("ShouldReadConfigPathFromEnv", func(t *testing.T) {
+		path := writeConfig(t, `{"storagePath": "/var/lib/rummikub"}`)
+		config, err := Load(nil, env(map[string]string{"RUMMIKUB_CONFIG": path}))
+		assert.NoError(t, err)
+		assert.Equal(t, config.StoragePath, "/var/lib/rummikub")
+	})
+	t.Run("ShouldSplitAllowedOrigins", func(t *testing.T) {
+		config, err := Load([]string{"-allowed-origins", "https://a.example, https://b.example"}, env(nil))
+		assert.NoError(t, err)
+		assert.Equal(t, config.AllowedOrigins, []string{"https://a.example", "https://b.example"})
+	})
+	t.Run("ShouldRejectUnknownFields", func(t *testing.T) {
+		path := writeConfig(t, `{"port": 8080}`)
+		_, err := Load([]string{"-config", path}, env(nil))
+		assert.Error(t, err)
+	})
+	t.Run("ShouldRejectInvalidEnv", func(t *testing.T) {
+		_, err := Load(nil, env(map[string]string{"RUMMIKUB_PONG_WAIT": "soon"}))
+		assert.EqualError(t, err, fmt.Sprintf(constants.InvalidConfigValue, "RUMMIKUB_PONG_WAIT", "soon"))
+	})
+	t.Run("ShouldValidate", func(t *testing.T) {
+		_, err := Load([]string{"-room-size", "5"}, env(nil))
+		assert.EqualError(t, err, constants.InvalidSeatCount)
+		_, err = Load([]string{"-min-reveal-delay", "0s"}, env(nil))
+		assert.EqualError(t, err, constants.InvalidTimer)
+		_, err = Load([]string{"-send-queue", "0"}, env(nil))
+		assert.EqualError(t, err, constants.InvalidLimit)
+		_, err = Load([]string{"-rate-limit", "0"}, env(nil))
+		assert.EqualError(t, err, constants.InvalidLimit)
+		_, err = Load([]string{"-log-level", "loud"}, env(nil))
+		assert.EqualError(t, err, fmt.Sprintf(constants.InvalidConfigValue, "log level", "loud"))
+	})
+}
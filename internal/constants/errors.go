@@ -7,22 +7,55 @@ const (
 	InvalidPiece            = string("piece is invalid")
 	InvalidCombineArguments = string("combine arguments must be pairs of type Set and int")
 	InvalidPieceSelection   = string("invalid piece selection")
+	InvalidPieceNotation    = string("invalid piece notation")
 	InvalidSetSelection     = string("invalid set selection")
 	InvalidNumberInput      = string("invalid input is not a number")
 	InvalidBoard            = string("board is invalid")
 	TooFewPieces            = string("not enough pieces to create set")
 	TooFewArguments         = string("not enough arguments provided")
+	TooManyArguments        = string("too many arguments provided")
+	ErrorAtColumn           = string("%s at column %d")
 	CannotInsert            = string("piece cannot be inserted into set")
 	CannotSplit             = string("set cannot be split")
 	WrongColorForRun        = string("piece does not match the color of the run")
 	WrongValueForGroup      = string("piece does not match the value of the group")
+	IndexOutOfBoundsFormat  = string("%s must be > %d and < %d")
+	DuplicatePiece          = string("piece selected more than once")
+	PieceNotInRack          = string("piece is not in the player's rack")
+	TablePiecesMissing      = string("board must use every piece on the table")
+	RackPiecesMismatch      = string("board must use exactly the selected rack pieces")
+	InvalidConfigFile       = string("config file %s: %v")
+	InvalidConfigValue      = string("invalid %s: %q")
+	InvalidTimer            = string("timers must be positive durations")
+	InvalidLimit            = string("limits must be positive")
+	Index                   = string("index")
 )
 
-// Returns ("name" must be > "min" and < "max")
-func IndexOutOfBounds(min, max int, name ...string) string {
-	label := "index"
+// Message is a format string from this package with the arguments it is
+// formatted with, kept apart so that each client can be sent it formatted in
+// their own language. Arguments that are messages are translated with it;
+// any other argument, such as a player's name, is used as it is.
+type Message struct {
+	Format string
+	Args   []any
+}
+
+// Format returns the message for the format string and arguments.
+func Format(format string, args ...any) Message {
+	return Message{format, args}
+}
+
+// Error returns the message formatted in English.
+func (m Message) Error() string {
+	return fmt.Sprintf(m.Format, m.Args...)
+}
+
+// Returns ("name" must be > "min" and < "max"). The name is a format string
+// from this package and is translated with the message.
+func IndexOutOfBounds(min, max int, name ...string) Message {
+	label := Index
 	if len(name) > 0 {
 		label = name[0]
 	}
-	return fmt.Sprintf("%s must be > %d and < %d", label, min, max)
+	return Format(IndexOutOfBoundsFormat, Format(label), min, max)
 }
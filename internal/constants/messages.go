@@ -0,0 +1,63 @@
+package constants
+
+const (
+	BoardHasInvalidSets     = string("board has invalid sets")
+	BoardHasLoosePieces     = string("board has loose pieces")
+	InitialMeldHasJoker     = string("initial meld cannot contain joker")
+	InitialMeldTooSmall     = string("initial meld must be worth at least %d points")
+	PlayerTurn              = string("%s's turn\n")
+	CommandError            = string("error performing %s: %s")
+	PlayerRenamed           = string("your name has been set to: %s")
+	LocaleChanged           = string("your language has been set to: %s")
+	UnsupportedLocale       = string("unsupported locale: %s")
+	BoardChanged            = string("cannot draw after changing the board")
+	PoolEmpty               = string("no pieces left to draw, turn passed")
+	Stalemate               = string("no pieces left and every player passed, game over")
+	InvalidMessage          = string("message is not a valid envelope")
+	UnsupportedVersion      = string("unsupported protocol version")
+	InvalidCommand          = string("invalid command")
+	NotEnoughPlayersToStart = string("not enough players to start game")
+	NotYourTurn             = string("it is not your turn")
+	AlreadyStarted          = string("game has already started")
+	InvalidSeatCount        = string("rooms must have between 1 and 4 seats")
+	RoomNotFound            = string("room not found")
+	InvalidSpectatorDelay   = string("spectator delay must be a duration such as 30s")
+	InvalidRevealRacks      = string("reveal must be true or false")
+	RevealRequiresDelay     = string("revealing racks requires a spectator delay of at least %s")
+	SpectatorCommand        = string("spectators cannot play or set up the game")
+	SpectatorWhisper        = string("spectators can only chat with other spectators")
+	ChatEmpty               = string("chat message is empty")
+	ChatTooLong             = string("chat messages must be at most %d characters")
+	UnknownRecipient        = string("no player named %s is connected")
+	InvalidHandSize         = string("hand size must be between 1 and %d")
+	InvalidInitialMeld      = string("initial meld cannot be negative")
+	InvalidSavedGame        = string("saved game is invalid")
+	RoomStopped             = string("room has been shut down")
+	InvalidSeat             = string("there is no seat %d")
+	NotHost                 = string("only the host can do that")
+	CannotKickHost          = string("the host cannot remove themselves")
+	Kicked                  = string("you were removed from the room by the host")
+	PlayerKicked            = string("%s was removed by the host")
+	GameNotStarted          = string("game has not started yet")
+	GamePaused              = string("the game is paused")
+	GameResumed             = string("the game has resumed")
+	AlreadyPaused           = string("game is already paused")
+	NotPaused               = string("game is not paused")
+	HostChanged             = string("%s is now the host")
+	RulesChanged            = string("rules: hand size %d, initial meld %d")
+	GameDealing             = string("pieces are being dealt")
+	GameFinished            = string("game is over")
+	GameAbandoned           = string("game was abandoned")
+	PlayerWon               = string("%s won the game")
+	RateLimited             = string("too many messages, slow down")
+	InputTooLong            = string("command input must be at most %d bytes")
+	InternalError           = string("something went wrong handling your message")
+	UnknownPlayer           = string("there is no player named %s")
+	MissingToken            = string("a session token is required")
+	UnknownToken            = string("session token does not hold a seat in this room")
+	RoomFull                = string("every seat in the room is taken")
+	InvalidEventCursor      = string("after must be an event number")
+	NameTaken               = string("another player is already named %s")
+	NotInPlay               = string("%s is no longer in the game")
+	NothingToUndo           = string("nothing to undo")
+)
@@ -1,5 +1,7 @@
 package event
 
+import "lets-play-rummikub/internal/constants"
+
 type Listener interface {
-	Notify(messages ...string)
+	Notify(messages ...constants.Message)
 }
@@ -0,0 +1,87 @@
+package locale
+
+import "lets-play-rummikub/internal/constants"
+
+var spanish = map[string]string{
+	constants.InvalidSet:              "el conjunto no es válido",
+	constants.InvalidPiece:            "la ficha no es válida",
+	constants.InvalidCombineArguments: "los argumentos de combinar deben ser pares de conjunto y número",
+	constants.InvalidPieceSelection:   "selección de ficha no válida",
+	constants.InvalidPieceNotation:    "notación de ficha no válida",
+	constants.InvalidSetSelection:     "selección de conjunto no válida",
+	constants.InvalidNumberInput:      "la entrada no es un número",
+	constants.InvalidBoard:            "el tablero no es válido",
+	constants.TooFewPieces:            "no hay suficientes fichas para crear el conjunto",
+	constants.TooFewArguments:         "no se proporcionaron suficientes argumentos",
+	constants.TooManyArguments:        "se proporcionaron demasiados argumentos",
+	constants.ErrorAtColumn:           "%s en la columna %d",
+	constants.CannotInsert:            "la ficha no se puede insertar en el conjunto",
+	constants.CannotSplit:             "el conjunto no se puede dividir",
+	constants.WrongColorForRun:        "la ficha no coincide con el color de la escalera",
+	constants.WrongValueForGroup:      "la ficha no coincide con el valor del grupo",
+	constants.IndexOutOfBoundsFormat:  "%s debe ser > %d y < %d",
+	constants.DuplicatePiece:          "la ficha se seleccionó más de una vez",
+	constants.PieceNotInRack:          "la ficha no está en el atril del jugador",
+	constants.TablePiecesMissing:      "el tablero debe usar todas las fichas de la mesa",
+	constants.RackPiecesMismatch:      "el tablero debe usar exactamente las fichas seleccionadas del atril",
+	constants.Index:                   "el índice",
+	constants.BoardHasInvalidSets:     "el tablero tiene conjuntos no válidos",
+	constants.BoardHasLoosePieces:     "el tablero tiene fichas sueltas",
+	constants.InitialMeldHasJoker:     "la jugada inicial no puede contener comodín",
+	constants.InitialMeldTooSmall:     "la jugada inicial debe valer al menos %d puntos",
+	constants.PlayerTurn:              "turno de %s\n",
+	constants.CommandError:            "error al realizar %s: %s",
+	constants.PlayerRenamed:           "tu nombre ahora es: %s",
+	constants.LocaleChanged:           "tu idioma ahora es: %s",
+	constants.UnsupportedLocale:       "idioma no compatible: %s",
+	constants.BoardChanged:            "no se puede robar después de cambiar el tablero",
+	constants.PoolEmpty:               "no quedan fichas para robar, se pasa el turno",
+	constants.Stalemate:               "no quedan fichas y todos los jugadores pasaron, fin de la partida",
+	constants.InvalidMessage:          "el mensaje no es un sobre válido",
+	constants.UnsupportedVersion:      "versión de protocolo no compatible",
+	constants.InvalidCommand:          "comando no válido",
+	constants.NotEnoughPlayersToStart: "no hay suficientes jugadores para empezar la partida",
+	constants.NotYourTurn:             "no es tu turno",
+	constants.AlreadyStarted:          "el juego ya ha comenzado",
+	constants.InvalidSeatCount:        "las salas deben tener entre 1 y 4 asientos",
+	constants.RoomNotFound:            "sala no encontrada",
+	constants.InvalidSpectatorDelay:   "el retraso para espectadores debe ser una duración como 30s",
+	constants.InvalidRevealRacks:      "reveal debe ser true o false",
+	constants.RevealRequiresDelay:     "mostrar los atriles requiere un retraso para espectadores de al menos %s",
+	constants.SpectatorCommand:        "los espectadores no pueden jugar ni preparar la partida",
+	constants.SpectatorWhisper:        "los espectadores solo pueden chatear con otros espectadores",
+	constants.ChatEmpty:               "el mensaje está vacío",
+	constants.ChatTooLong:             "los mensajes deben tener como máximo %d caracteres",
+	constants.UnknownRecipient:        "no hay ningún jugador conectado llamado %s",
+	constants.InvalidHandSize:         "el tamaño de la mano debe estar entre 1 y %d",
+	constants.InvalidInitialMeld:      "la jugada inicial no puede ser negativa",
+	constants.InvalidSavedGame:        "la partida guardada no es válida",
+	constants.RoomStopped:             "la sala se ha cerrado",
+	constants.InvalidSeat:             "no hay asiento %d",
+	constants.NotHost:                 "solo el anfitrión puede hacer eso",
+	constants.CannotKickHost:          "el anfitrión no puede expulsarse a sí mismo",
+	constants.Kicked:                  "el anfitrión te expulsó de la sala",
+	constants.PlayerKicked:            "el anfitrión expulsó a %s",
+	constants.GameNotStarted:          "el juego aún no ha comenzado",
+	constants.GamePaused:              "el juego está en pausa",
+	constants.GameResumed:             "el juego se ha reanudado",
+	constants.AlreadyPaused:           "el juego ya está en pausa",
+	constants.NotPaused:               "el juego no está en pausa",
+	constants.HostChanged:             "%s es ahora el anfitrión",
+	constants.RulesChanged:            "reglas: tamaño de mano %d, jugada inicial %d",
+	constants.GameDealing:             "se están repartiendo las fichas",
+	constants.GameFinished:            "el juego ha terminado",
+	constants.GameAbandoned:           "el juego fue abandonado",
+	constants.PlayerWon:               "%s ganó el juego",
+	constants.RateLimited:             "demasiados mensajes, más despacio",
+	constants.InputTooLong:            "la entrada del comando debe tener como máximo %d bytes",
+	constants.InternalError:           "algo salió mal al procesar tu mensaje",
+	constants.UnknownPlayer:           "no hay ningún jugador llamado %s",
+	constants.MissingToken:            "se necesita un token de sesión",
+	constants.UnknownToken:            "el token de sesión no ocupa un asiento en esta sala",
+	constants.RoomFull:                "todos los asientos de la sala están ocupados",
+	constants.InvalidEventCursor:      "after debe ser un número de evento",
+	constants.NameTaken:               "ya hay otro jugador llamado %s",
+	constants.NotInPlay:               "%s ya no está en la partida",
+	constants.NothingToUndo:           "no hay nada que deshacer",
+}
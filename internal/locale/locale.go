@@ -0,0 +1,79 @@
+package locale
+
+import (
+	"errors"
+	"fmt"
+	"lets-play-rummikub/internal/constants"
+	"strings"
+)
+
+type Locale string
+
+const (
+	English Locale = "en"
+	Spanish Locale = "es"
+	Tagalog Locale = "tl"
+)
+
+var catalogs = map[Locale]map[string]string{
+	Spanish: spanish,
+	Tagalog: tagalog,
+}
+
+// Parse accepts a language tag such as "es", "es-MX" or an Accept-Language
+// header value and returns the first supported locale.
+func Parse(tag string) (Locale, bool) {
+	for _, option := range strings.Split(tag, ",") {
+		option, _, _ = strings.Cut(option, ";")
+		option, _, _ = strings.Cut(strings.TrimSpace(option), "-")
+		option, _, _ = strings.Cut(option, "_")
+		locale := Locale(strings.ToLower(option))
+		if locale.IsSupported() {
+			return locale, true
+		}
+	}
+	return English, false
+}
+
+func (l Locale) IsSupported() bool {
+	if l == English {
+		return true
+	}
+	_, ok := catalogs[l]
+	return ok
+}
+
+// Translate returns the catalog's translation of a format string from the
+// constants package, or the format string itself.
+func (l Locale) Translate(format string) string {
+	if translated, ok := catalogs[l][format]; ok {
+		return translated
+	}
+	return format
+}
+
+// Sprintf formats the arguments with the translated format string. Errors
+// among the arguments, such as a nested constants.Message, are written in
+// the locale too. Any other argument, such as a player's name, is written as
+// it is.
+func (l Locale) Sprintf(format string, args ...any) string {
+	localized := make([]any, len(args))
+	for i, arg := range args {
+		localized[i] = arg
+		if err, ok := arg.(error); ok {
+			localized[i] = l.Localize(err)
+		}
+	}
+	return fmt.Sprintf(l.Translate(format), localized...)
+}
+
+// Localize returns the error's message in the locale. An error that is or
+// wraps a constants.Message is formatted from its format string and
+// arguments; any other error is looked up by its text.
+func (l Locale) Localize(err error) string {
+	var message constants.Message
+	if errors.As(err, &message) {
+		return l.Sprintf(message.Format, message.Args...)
+	}
+	return l.Translate(err.Error())
+}
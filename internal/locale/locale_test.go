@@ -0,0 +1,82 @@
+package locale
+
+import (
+	"errors"
+	"fmt"
+	"lets-play-rummikub/internal/constants"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	t.Run("ShouldParseLanguage", func(t *testing.T) {
+		locale, ok := Parse("es")
+		assert.True(t, ok)
+		assert.Equal(t, locale, Spanish)
+	})
+	t.Run("ShouldParseRegion", func(t *testing.T) {
+		locale, ok := Parse("tl-PH")
+		assert.True(t, ok)
+		assert.Equal(t, locale, Tagalog)
+	})
+	t.Run("ShouldParseAcceptLanguage", func(t *testing.T) {
+		locale, ok := Parse("fr-CH, fr;q=0.9, es;q=0.8, *;q=0.5")
+		assert.True(t, ok)
+		assert.Equal(t, locale, Spanish)
+	})
+	t.Run("ShouldDefaultToEnglish", func(t *testing.T) {
+		locale, ok := Parse("fr")
+		assert.False(t, ok)
+		assert.Equal(t, locale, English)
+	})
+}
+
+func TestTranslate(t *testing.T) {
+	t.Run("ShouldTranslateMessage", func(t *testing.T) {
+		assert.Equal(t, Spanish.Translate(constants.InvalidSet), "el conjunto no es válido")
+	})
+	t.Run("ShouldReturnUnknownMessage", func(t *testing.T) {
+		assert.Equal(t, Spanish.Translate("Player 1: hello"), "Player 1: hello")
+	})
+	t.Run("ShouldNotTranslateEnglish", func(t *testing.T) {
+		assert.Equal(t, English.Translate(constants.InvalidSet), constants.InvalidSet)
+	})
+}
+
+func TestSprintf(t *testing.T) {
+	t.Run("ShouldFormatInLocale", func(t *testing.T) {
+		assert.Equal(t, Spanish.Sprintf(constants.PlayerTurn, "Player 2"), "turno de Player 2\n")
+		assert.Equal(t, Tagalog.Sprintf(constants.PlayerTurn, "Player 2"), "turno na ni Player 2\n")
+		assert.Equal(t, English.Sprintf(constants.PlayerRenamed, "Ana"), "your name has been set to: Ana")
+	})
+	t.Run("ShouldNotTranslateStringArguments", func(t *testing.T) {
+		assert.Equal(t, Spanish.Sprintf(constants.PlayerTurn, constants.InvalidSet), "turno de set is invalid\n")
+	})
+	t.Run("ShouldTranslateErrorArguments", func(t *testing.T) {
+		message := Spanish.Sprintf(constants.CommandError, "insert", errors.New(constants.InvalidPieceSelection))
+		assert.Equal(t, message, "error al realizar insert: selección de ficha no válida")
+	})
+}
+
+func TestLocalize(t *testing.T) {
+	t.Run("ShouldTranslateError", func(t *testing.T) {
+		assert.Equal(t, Spanish.Localize(errors.New(constants.InvalidSet)), "el conjunto no es válido")
+	})
+	t.Run("ShouldFormatMessage", func(t *testing.T) {
+		assert.Equal(t, Spanish.Localize(constants.Format(constants.InitialMeldTooSmall, 30)), "la jugada inicial debe valer al menos 30 puntos")
+	})
+	t.Run("ShouldFormatNestedMessage", func(t *testing.T) {
+		message := constants.Format(constants.CommandError, "split", constants.IndexOutOfBounds(0, 2))
+		assert.Equal(t, Spanish.Localize(message), "error al realizar split: el índice debe ser > 0 y < 2")
+	})
+	t.Run("ShouldFormatWrappedMessage", func(t *testing.T) {
+		message := fmt.Errorf("%w", constants.Format(constants.ErrorAtColumn, errors.New(constants.InvalidNumberInput), 6))
+		assert.Equal(t, Spanish.Localize(message), "la entrada no es un número en la columna 6")
+	})
+	t.Run("ShouldKeepPlayerNames", func(t *testing.T) {
+		message := constants.Format(constants.UnknownPlayer, constants.Index)
+		assert.Equal(t, English.Localize(message), message.Error())
+		assert.NotContains(t, Spanish.Localize(message), "el índice")
+	})
+}
@@ -0,0 +1,87 @@
+package locale
+
+import "lets-play-rummikub/internal/constants"
+
+var tagalog = map[string]string{
+	constants.InvalidSet:              "hindi wasto ang set",
+	constants.InvalidPiece:            "hindi wasto ang tile",
+	constants.InvalidCombineArguments: "ang mga argumento ng combine ay dapat magkapares na set at numero",
+	constants.InvalidPieceSelection:   "hindi wasto ang napiling tile",
+	constants.InvalidPieceNotation:    "hindi wasto ang notasyon ng tile",
+	constants.InvalidSetSelection:     "hindi wasto ang napiling set",
+	constants.InvalidNumberInput:      "hindi numero ang input",
+	constants.InvalidBoard:            "hindi wasto ang board",
+	constants.TooFewPieces:            "kulang ang mga tile para makabuo ng set",
+	constants.TooFewArguments:         "kulang ang mga ibinigay na argumento",
+	constants.TooManyArguments:        "sobra ang mga ibinigay na argumento",
+	constants.ErrorAtColumn:           "%s sa column %d",
+	constants.CannotInsert:            "hindi maisisingit ang tile sa set",
+	constants.CannotSplit:             "hindi mahahati ang set",
+	constants.WrongColorForRun:        "hindi tugma ang kulay ng tile sa run",
+	constants.WrongValueForGroup:      "hindi tugma ang halaga ng tile sa group",
+	constants.IndexOutOfBoundsFormat:  "ang %s ay dapat > %d at < %d",
+	constants.DuplicatePiece:          "higit sa isang beses napili ang tile",
+	constants.PieceNotInRack:          "wala ang tile sa rack ng manlalaro",
+	constants.TablePiecesMissing:      "dapat gamitin ng board ang bawat tile sa mesa",
+	constants.RackPiecesMismatch:      "dapat gamitin ng board ang eksaktong mga napiling tile mula sa rack",
+	constants.Index:                   "index",
+	constants.BoardHasInvalidSets:     "may hindi wastong set sa board",
+	constants.BoardHasLoosePieces:     "may maluwag na tile sa board",
+	constants.InitialMeldHasJoker:     "hindi puwedeng may joker ang unang meld",
+	constants.InitialMeldTooSmall:     "ang unang meld ay dapat may halagang hindi bababa sa %d puntos",
+	constants.PlayerTurn:              "turno na ni %s\n",
+	constants.CommandError:            "error sa pag-%s: %s",
+	constants.PlayerRenamed:           "ang pangalan mo ay naitakda na sa: %s",
+	constants.LocaleChanged:           "ang wika mo ay naitakda na sa: %s",
+	constants.UnsupportedLocale:       "hindi suportadong wika: %s",
+	constants.BoardChanged:            "hindi puwedeng bumunot matapos baguhin ang board",
+	constants.PoolEmpty:               "wala nang tile na mabubunot, ipinasa ang turno",
+	constants.Stalemate:               "wala nang tile at pumasa ang lahat ng manlalaro, tapos na ang laro",
+	constants.InvalidMessage:          "hindi wastong envelope ang mensahe",
+	constants.UnsupportedVersion:      "hindi suportadong bersyon ng protocol",
+	constants.InvalidCommand:          "hindi wastong command",
+	constants.NotEnoughPlayersToStart: "kulang ang mga manlalaro para simulan ang laro",
+	constants.NotYourTurn:             "hindi mo pa turno",
+	constants.AlreadyStarted:          "nagsimula na ang laro",
+	constants.InvalidSeatCount:        "ang mga kuwarto ay dapat may 1 hanggang 4 na upuan",
+	constants.RoomNotFound:            "hindi nahanap ang kuwarto",
+	constants.InvalidSpectatorDelay:   "ang delay para sa manonood ay dapat tagal gaya ng 30s",
+	constants.InvalidRevealRacks:      "ang reveal ay dapat true o false",
+	constants.RevealRequiresDelay:     "kailangan ng delay para sa manonood na hindi bababa sa %s bago ipakita ang mga rack",
+	constants.SpectatorCommand:        "hindi makakalaro o makakapag-ayos ng laro ang mga manonood",
+	constants.SpectatorWhisper:        "sa kapwa manonood lang puwedeng makipag-chat ang mga manonood",
+	constants.ChatEmpty:               "walang laman ang mensahe",
+	constants.ChatTooLong:             "hanggang %d na character lang ang mensahe",
+	constants.UnknownRecipient:        "walang nakakonektang manlalaro na nagngangalang %s",
+	constants.InvalidHandSize:         "ang dami ng tile sa kamay ay dapat mula 1 hanggang %d",
+	constants.InvalidInitialMeld:      "hindi puwedeng negatibo ang unang meld",
+	constants.InvalidSavedGame:        "hindi wasto ang naka-save na laro",
+	constants.RoomStopped:             "isinara na ang kuwarto",
+	constants.InvalidSeat:             "walang upuan %d",
+	constants.NotHost:                 "ang host lang ang makakagawa niyan",
+	constants.CannotKickHost:          "hindi maaaring alisin ng host ang sarili",
+	constants.Kicked:                  "inalis ka ng host sa silid",
+	constants.PlayerKicked:            "inalis ng host si %s",
+	constants.GameNotStarted:          "hindi pa nagsisimula ang laro",
+	constants.GamePaused:              "naka-pause ang laro",
+	constants.GameResumed:             "nagpatuloy na ang laro",
+	constants.AlreadyPaused:           "naka-pause na ang laro",
+	constants.NotPaused:               "hindi naka-pause ang laro",
+	constants.HostChanged:             "si %s na ang host",
+	constants.RulesChanged:            "patakaran: laki ng kamay %d, unang meld %d",
+	constants.GameDealing:             "ipinamimigay pa ang mga tile",
+	constants.GameFinished:            "tapos na ang laro",
+	constants.GameAbandoned:           "iniwan na ang laro",
+	constants.PlayerWon:               "nanalo si %s sa laro",
+	constants.RateLimited:             "masyadong maraming mensahe, dahan-dahan lang",
+	constants.InputTooLong:            "ang input ng utos ay dapat hindi hihigit sa %d byte",
+	constants.InternalError:           "nagkaproblema sa pagproseso ng iyong mensahe",
+	constants.UnknownPlayer:           "walang manlalarong nagngangalang %s",
+	constants.MissingToken:            "kailangan ng session token",
+	constants.UnknownToken:            "walang upuan sa kuwartong ito ang session token",
+	constants.RoomFull:                "okupado na ang lahat ng upuan sa kuwarto",
+	constants.InvalidEventCursor:      "dapat numero ng event ang after",
+	constants.NameTaken:               "may ibang manlalaro nang nagngangalang %s",
+	constants.NotInPlay:               "wala na sa laro si %s",
+	constants.NothingToUndo:           "walang maa-undo",
+}
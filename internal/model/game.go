@@ -7,6 +7,7 @@ import (
 	"lets-play-rummikub/internal/constants"
 	"lets-play-rummikub/internal/event"
 	"math/rand"
+	"slices"
 	"time"
 )
 
@@ -22,13 +23,31 @@ type (
 		HasPiece
 		AddLoosePiece(piece Piece)
 		RemovePieces(piece ...Piece)
+		PieceID(piece Piece) int
+		PieceByID(id int) (Piece, error)
+		PieceCopy(piece Piece) int
+		TablePieces() []Piece
+		ReplaceBoard(sets ...Set)
 		IsValidBoard() bool
 		CurrentPlayer() Player
 		Player(index int) Player
-		NextTurn() bool
+		SwapSeats(i, j int) error
+		RemovePlayer(seat int) error
+		InPlay(seat int) bool
+		NextTurn() error
+		Draw() error
+		ConsecutivePasses() int
+		IsStalemate() bool
+		IsGameOver() bool
 		TotalPlayers() int
 		MarshalJSON() ([]byte, error)
-		Notify(message ...string)
+		MarshalRack(player Player) ([]byte, error)
+		View(seat int) View
+		SpectatorView(reveal bool) View
+		Rules() Rules
+		SetRules(Rules) error
+		MarshalState() ([]byte, error)
+		Notify(message ...constants.Message)
 		SetNotifier(event.Listener)
 		Clone() Game
 		Restore(game Game)
@@ -36,33 +55,108 @@ type (
 
 	instance struct {
 		event.Listener
-		firstMeldComplete    bool
+		melded               []bool
+		removed              []bool
 		tiles                []Piece
+		pieces               []Piece
 		board                []Set
 		loose                []Piece
 		players              []Player
 		currentPlayer        int
 		currentPlayerRackLen int
+		turnStart            []Set
+		passes               int
+		rules                Rules
 	}
 )
 
-func (g *instance) Notify(messages ...string) {
+func (g *instance) Notify(messages ...constants.Message) {
 	if g.Listener != nil {
 		g.Listener.Notify(messages...)
 	}
 }
 
 func (g *instance) MarshalJSON() ([]byte, error) {
+	board := make([]setOutput, len(g.board))
+	for i, s := range g.board {
+		board[i] = setOutput{g.identify(s.(*set).tiles)}
+	}
+	output := struct {
+		Board  []setOutput `json:"board"`
+		Pieces []any       `json:"piece"`
+	}{
+		board,
+		g.identify(g.loose),
+	}
+	return json.Marshal(output)
+}
+
+func (g *instance) MarshalRack(player Player) ([]byte, error) {
+	rack := make([]Piece, player.RackLen())
+	for i := range rack {
+		rack[i], _ = player.Piece(i)
+	}
 	output := struct {
-		Board  []Set   `json:"board"`
-		Pieces []Piece `json:"piece"`
+		Rack []any `json:"rack"`
 	}{
-		g.board,
-		g.loose,
+		g.identify(rack),
 	}
 	return json.Marshal(output)
 }
 
+type setOutput struct {
+	Pieces []any `json:"pieces"`
+}
+
+func (g *instance) identify(pieces []Piece) []any {
+	output := make([]any, len(pieces))
+	for i, p := range pieces {
+		output[i] = p.(*piece).output(g.PieceID(p))
+	}
+	return output
+}
+
+// PieceID returns the 1-based ID of a piece created by this game, or 0.
+func (g *instance) PieceID(p Piece) int {
+	for index, created := range g.pieces {
+		if created == p {
+			return index + 1
+		}
+	}
+	return 0
+}
+
+// PieceCopy returns which of the identical copies of a piece this is,
+// 1 or 2, based on the order the tiles were created in, or 0.
+func (g *instance) PieceCopy(p Piece) int {
+	id := g.PieceID(p)
+	if id == 0 {
+		return 0
+	}
+	return (id-1)/(len(g.pieces)/2) + 1
+}
+
+func (g *instance) PieceByID(id int) (Piece, error) {
+	if id < 1 || id > len(g.pieces) {
+		return nil, errors.New(constants.InvalidPieceSelection)
+	}
+	return g.pieces[id-1], nil
+}
+
+// TablePieces returns every piece on the board, including loose pieces.
+func (g *instance) TablePieces() []Piece {
+	pieces := make([]Piece, 0)
+	for _, s := range g.board {
+		pieces = append(pieces, s.(*set).tiles...)
+	}
+	return append(pieces, g.loose...)
+}
+
+func (g *instance) ReplaceBoard(sets ...Set) {
+	g.board = sets
+	g.loose = make([]Piece, 0)
+}
+
 func (g *instance) createTiles() {
 	g.tiles = make([]Piece, 106)
 	index := 0
@@ -76,13 +170,20 @@ func (g *instance) createTiles() {
 		g.tiles[index] = NewPiece(ValueJoker, ColorBlack)
 		index++
 	}
+	g.pieces = make([]Piece, len(g.tiles))
+	copy(g.pieces, g.tiles)
+}
+
+// DefaultName returns the name a player is given for a seat.
+func DefaultName(seat int) string {
+	return fmt.Sprintf("Player %d", seat+1)
 }
 
 func (g *instance) createPlayers(totalPlayers int) {
 	g.players = make([]Player, 0, totalPlayers)
 	for i := 0; i < int(totalPlayers); i++ {
 		player := NewPlayer()
-		player.SetName(fmt.Sprintf("Player %d", i+1))
+		player.SetName(DefaultName(i))
 		g.players = append(g.players, player)
 	}
 }
@@ -92,18 +193,21 @@ func NewGame(totalPlayers uint) Game {
 		return nil
 	}
 	instance := new(instance)
-	instance.firstMeldComplete = false
 	instance.board = make([]Set, 0)
 	instance.loose = make([]Piece, 0)
 	instance.createTiles()
 	instance.createPlayers(int(totalPlayers))
+	instance.melded = make([]bool, totalPlayers)
+	instance.removed = make([]bool, totalPlayers)
+	instance.rules = DefaultRules()
 	instance.currentPlayer = 0
-	instance.currentPlayerRackLen = instance.CurrentPlayer().RackLen()
+	instance.startTurn()
 	return instance
 }
 
 func (game *instance) Clone() Game {
 	newGame := new(instance)
+	newGame.pieces = game.pieces
 	board := make([]Set, len(game.board))
 	for i := range game.board {
 		board[i] = game.board[i].Clone()
@@ -177,11 +281,11 @@ func (g *instance) RemovePieces(pieces ...Piece) {
 
 func (g *instance) DealPieces() {
 	for _, player := range g.players {
-		for i := 0; i < 14; i++ {
+		for i := 0; i < g.rules.HandSize; i++ {
 			player.DealPiece(g.TakePiece())
 		}
 	}
-	g.currentPlayerRackLen = g.CurrentPlayer().RackLen()
+	g.startTurn()
 }
 
 func (g *instance) ReplaceSet(existing, replace Set) {
@@ -210,6 +314,50 @@ func (g *instance) Player(index int) Player {
 	return g.players[index]
 }
 
+// SwapSeats exchanges the players in two seats, along with their racks and
+// whether they have melded.
+func (g *instance) SwapSeats(i, j int) error {
+	for _, seat := range []int{i, j} {
+		if seat < 0 || seat >= len(g.players) {
+			return constants.Format(constants.InvalidSeat, seat+1)
+		}
+	}
+	g.players[i], g.players[j] = g.players[j], g.players[i]
+	g.melded[i], g.melded[j] = g.melded[j], g.melded[i]
+	g.removed[i], g.removed[j] = g.removed[j], g.removed[i]
+	return nil
+}
+
+// RemovePlayer takes the player in the seat out of play. Their rack stays
+// as it is and their turns are skipped. When it is their turn, the turn
+// passes to the next player, so any moves must be undone first.
+func (g *instance) RemovePlayer(seat int) error {
+	if seat < 0 || seat >= len(g.players) {
+		return constants.Format(constants.InvalidSeat, seat+1)
+	}
+	g.removed[seat] = true
+	if seat == g.currentPlayer {
+		g.advanceTurn()
+	}
+	return nil
+}
+
+// InPlay reports whether the seat still takes turns.
+func (g *instance) InPlay(seat int) bool {
+	return seat >= 0 && seat < len(g.players) && !g.removed[seat]
+}
+
+// playersInPlay counts the seats that still take turns.
+func (g *instance) playersInPlay() int {
+	count := 0
+	for seat := range g.players {
+		if g.InPlay(seat) {
+			count++
+		}
+	}
+	return count
+}
+
 func (g *instance) CurrentPlayer() Player {
 	return g.players[g.currentPlayer]
 }
@@ -218,39 +366,119 @@ func (g *instance) TotalPlayers() int {
 	return len(g.players)
 }
 
-func (g *instance) NextTurn() bool {
+func (g *instance) NextTurn() error {
+	if g.IsStalemate() {
+		return errors.New(constants.Stalemate)
+	}
 	if !g.IsValidBoard() {
-		g.Notify("board has invalid sets")
-		return false
+		return errors.New(constants.BoardHasInvalidSets)
 	}
 	if g.hasLoosePieces() {
-		g.Notify("board has loose pieces")
-		return false
+		return errors.New(constants.BoardHasLoosePieces)
 	}
-	if !g.firstMeldComplete && len(g.board) > 0 {
-		if g.hasSetWithJoker() {
-			g.Notify("initial meld cannot contain joker")
-			return false
+	if !g.melded[g.currentPlayer] && g.CurrentPlayer().RackLen() < g.currentPlayerRackLen {
+		placed := g.placedPieces()
+		if hasJoker(placed) {
+			return errors.New(constants.InitialMeldHasJoker)
 		}
-		if !g.hasSetOverThirty() {
-			g.Notify("initial meld must sum > 30")
-			return false
+		if !g.hasInitialMeldSet(placed) {
+			return constants.Format(constants.InitialMeldTooSmall, g.rules.InitialMeld)
 		}
-		g.firstMeldComplete = true
+		g.melded[g.currentPlayer] = true
+	}
+	if g.CurrentPlayer().RackLen() >= g.currentPlayerRackLen {
+		g.drawPiece()
+	} else {
+		g.passes = 0
+	}
+	g.advanceTurn()
+	return nil
+}
+
+// Draw ends the turn by drawing a piece, or passes when the pool is empty.
+// It is only allowed while the board is unchanged from the start of the turn.
+func (g *instance) Draw() error {
+	if g.IsStalemate() {
+		return errors.New(constants.Stalemate)
+	}
+	if g.isDirty() {
+		return errors.New(constants.BoardChanged)
 	}
-	currentPlayer := g.CurrentPlayer()
-	if currentPlayer.RackLen() >= g.currentPlayerRackLen {
-		currentPlayer.DealPiece(g.TakePiece())
+	g.drawPiece()
+	g.advanceTurn()
+	return nil
+}
+
+func (g *instance) drawPiece() {
+	piece := g.TakePiece()
+	if piece == nil {
+		g.passes++
+		g.Notify(constants.Format(constants.PoolEmpty))
+		return
 	}
-	g.currentPlayer = (g.currentPlayer + 1) % len(g.players)
-	g.Notify(fmt.Sprintf("%s's turn\n", g.CurrentPlayer().Name()))
+	g.passes = 0
+	g.CurrentPlayer().DealPiece(piece)
+}
+
+func (g *instance) advanceTurn() {
+	if g.IsStalemate() {
+		g.Notify(constants.Format(constants.Stalemate))
+		return
+	}
+	for next := 1; next <= len(g.players); next++ {
+		if seat := (g.currentPlayer + next) % len(g.players); g.InPlay(seat) {
+			g.currentPlayer = seat
+			break
+		}
+	}
+	g.Notify(constants.Format(constants.PlayerTurn, g.CurrentPlayer().Name()))
+	g.startTurn()
+}
+
+func (g *instance) startTurn() {
 	g.currentPlayerRackLen = g.CurrentPlayer().RackLen()
-	return true
+	g.turnStart = make([]Set, len(g.board))
+	copy(g.turnStart, g.board)
+}
+
+// isDirty reports whether the current player has changed the table or
+// their rack since the start of the turn.
+func (g *instance) isDirty() bool {
+	if g.hasLoosePieces() || g.CurrentPlayer().RackLen() != g.currentPlayerRackLen {
+		return true
+	}
+	if len(g.board) != len(g.turnStart) {
+		return true
+	}
+	for i := range g.board {
+		current, start := g.board[i].(*set), g.turnStart[i].(*set)
+		if len(current.tiles) != len(start.tiles) {
+			return true
+		}
+		for j := range current.tiles {
+			if current.tiles[j] != start.tiles[j] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (g *instance) ConsecutivePasses() int {
+	return g.passes
 }
 
+// IsStalemate reports whether the pool is empty and every player in play
+// has passed in a row.
+func (g *instance) IsStalemate() bool {
+	return len(g.tiles) == 0 && g.passes >= g.playersInPlay()
+}
+
+// IsGameOver reports whether a player has emptied their rack. A rack of
+// jokers scores nothing but has not gone out.
 func (g *instance) IsGameOver() bool {
 	for _, p := range g.players {
-		if p.Score() == 0 {
+		if p.RackLen() == 0 {
 			return true
 		}
 	}
@@ -289,19 +517,42 @@ func (g *instance) AddSet(set Set) {
 	g.board = append(g.board, set)
 }
 
-func (game *instance) hasSetOverThirty() bool {
-	overThirtyPoints := false
-	for _, set := range game.board {
-		if set.Size() >= 30 {
-			overThirtyPoints = true
+// placedPieces returns the pieces on the board that were not on it at the
+// start of the turn, which the current player placed from their rack.
+func (game *instance) placedPieces() map[Piece]bool {
+	start := make(map[Piece]bool)
+	for _, s := range game.turnStart {
+		for _, p := range s.(*set).tiles {
+			start[p] = true
 		}
 	}
-	return overThirtyPoints
+	placed := make(map[Piece]bool)
+	for _, s := range game.board {
+		for _, p := range s.(*set).tiles {
+			if !start[p] {
+				placed[p] = true
+			}
+		}
+	}
+	return placed
 }
 
-func (game *instance) hasSetWithJoker() bool {
-	for _, set := range game.board {
-		if set.NumberOfJokers() > 0 {
+// hasInitialMeldSet reports whether the board has a set made only of placed
+// pieces worth at least the initial meld. Sets already on the table do not
+// count towards it.
+func (game *instance) hasInitialMeldSet(placed map[Piece]bool) bool {
+	for _, s := range game.board {
+		own := !slices.ContainsFunc(s.(*set).tiles, func(p Piece) bool { return !placed[p] })
+		if own && s.Size() >= game.rules.InitialMeld {
+			return true
+		}
+	}
+	return false
+}
+
+func hasJoker(pieces map[Piece]bool) bool {
+	for p := range pieces {
+		if p.IsJoker() {
 			return true
 		}
 	}
@@ -310,4 +561,4 @@ func (game *instance) hasSetWithJoker() bool {
 
 func (game *instance) SetNotifier(n event.Listener) {
 	game.Listener = n
-}
\ No newline at end of file
+}
@@ -1,6 +1,8 @@
 package model
 
 import (
+	"fmt"
+	"lets-play-rummikub/internal/constants"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -60,3 +62,148 @@ func TestDealPieces(t *testing.T) {
 		assert.Len(t, p.(*player).rack, 14)
 	}
 }
+
+func TestPieceID(t *testing.T) {
+	game := NewGame(1)
+	piece, err := game.PieceByID(1)
+	assert.NoError(t, err)
+	assert.Equal(t, game.PieceID(piece), 1)
+	joker, err := game.PieceByID(106)
+	assert.NoError(t, err)
+	assert.True(t, joker.IsJoker())
+	_, err = game.PieceByID(107)
+	assert.Error(t, err)
+	assert.Equal(t, game.PieceID(NewPiece(Value(1), ColorBlack)), 0)
+}
+
+func TestDraw(t *testing.T) {
+	t.Run("ShouldDrawPiece", func(t *testing.T) {
+		game := NewGame(2)
+		err := game.Draw()
+		assert.NoError(t, err)
+		assert.Equal(t, game.Player(0).RackLen(), 1)
+		assert.Same(t, game.CurrentPlayer(), game.Player(1))
+		assert.Equal(t, game.ConsecutivePasses(), 0)
+	})
+	t.Run("ShouldRejectDirtyBoard", func(t *testing.T) {
+		game := NewGame(2)
+		game.AddLoosePiece(NewPiece(Value(1), ColorBlack))
+		err := game.Draw()
+		assert.EqualError(t, err, constants.BoardChanged)
+		assert.Same(t, game.CurrentPlayer(), game.Player(0))
+	})
+	t.Run("ShouldRejectChangedSet", func(t *testing.T) {
+		game := NewGame(2)
+		set := Combine(NewPiece(Value(1), ColorBlack), NewPiece(Value(2), ColorBlack), NewPiece(Value(3), ColorBlack))
+		game.AddSet(set)
+		game.(*instance).startTurn()
+		inserted, err := set.Insert(NewPiece(Value(4), ColorBlack), 3)
+		assert.NoError(t, err)
+		game.ReplaceSet(set, inserted)
+		assert.EqualError(t, game.Draw(), constants.BoardChanged)
+		game.ReplaceSet(inserted, set)
+		assert.NoError(t, game.Draw())
+	})
+	t.Run("ShouldPassOnEmptyPool", func(t *testing.T) {
+		game := NewGame(2)
+		game.(*instance).tiles = nil
+		assert.NoError(t, game.Draw())
+		assert.Equal(t, game.Player(0).RackLen(), 0)
+		assert.Equal(t, game.ConsecutivePasses(), 1)
+		assert.False(t, game.IsStalemate())
+		assert.NoError(t, game.Draw())
+		assert.Equal(t, game.ConsecutivePasses(), 2)
+		assert.True(t, game.IsStalemate())
+		assert.EqualError(t, game.Draw(), constants.Stalemate)
+		assert.EqualError(t, game.NextTurn(), constants.Stalemate)
+	})
+}
+
+// placeFromRack deals the pieces to the current player, starts their turn
+// and plays the pieces onto the board as a new set.
+func placeFromRack(game Game, pieces ...Piece) {
+	for _, p := range pieces {
+		game.CurrentPlayer().DealPiece(p)
+	}
+	game.(*instance).startTurn()
+	game.CurrentPlayer().RemovePiece(pieces...)
+	game.AddSet(Combine(pieces...))
+}
+
+func TestNextTurn(t *testing.T) {
+	t.Run("ShouldMeldBesideOpponentJoker", func(t *testing.T) {
+		game := NewGame(2)
+		game.AddSet(Combine(NewPiece(ValueJoker, ColorBlack), NewPiece(Value(2), ColorRed), NewPiece(Value(3), ColorRed)))
+		placeFromRack(game, NewPiece(Value(10), ColorBlue), NewPiece(Value(11), ColorBlue), NewPiece(Value(12), ColorBlue))
+		assert.NoError(t, game.NextTurn())
+		assert.True(t, game.(*instance).melded[0])
+	})
+	t.Run("ShouldRejectPlacedJoker", func(t *testing.T) {
+		game := NewGame(2)
+		placeFromRack(game, NewPiece(ValueJoker, ColorBlack), NewPiece(Value(12), ColorBlue), NewPiece(Value(13), ColorBlue))
+		assert.EqualError(t, game.NextTurn(), constants.InitialMeldHasJoker)
+	})
+	t.Run("ShouldNotCountOpponentSets", func(t *testing.T) {
+		game := NewGame(2)
+		game.AddSet(Combine(NewPiece(Value(11), ColorRed), NewPiece(Value(12), ColorRed), NewPiece(Value(13), ColorRed)))
+		placeFromRack(game, NewPiece(Value(1), ColorBlue), NewPiece(Value(2), ColorBlue), NewPiece(Value(3), ColorBlue))
+		assert.EqualError(t, game.NextTurn(), fmt.Sprintf(constants.InitialMeldTooSmall, 30))
+		assert.False(t, game.(*instance).melded[0])
+	})
+}
+
+func TestIsGameOver(t *testing.T) {
+	t.Run("ShouldNotEndWithJokerRack", func(t *testing.T) {
+		game := NewGame(2)
+		game.DealPieces()
+		game.Player(0).(*player).rack = []Piece{NewPiece(ValueJoker, ColorBlack)}
+		assert.Equal(t, game.Player(0).Score(), uint16(0))
+		assert.False(t, game.IsGameOver())
+		game.Player(0).(*player).rack = nil
+		assert.True(t, game.IsGameOver())
+	})
+}
+
+func TestSwapSeats(t *testing.T) {
+	t.Run("ShouldSwapPlayers", func(t *testing.T) {
+		game := NewGame(3)
+		first, third := game.Player(0), game.Player(2)
+		game.(*instance).melded[0] = true
+		assert.NoError(t, game.SwapSeats(0, 2))
+		assert.Equal(t, game.Player(0), third)
+		assert.Equal(t, game.Player(2), first)
+		assert.Equal(t, game.(*instance).melded, []bool{false, false, true})
+	})
+	t.Run("ShouldRejectMissingSeat", func(t *testing.T) {
+		game := NewGame(2)
+		assert.EqualError(t, game.SwapSeats(0, 2), fmt.Sprintf(constants.InvalidSeat, 3))
+	})
+}
+
+func TestRemovePlayer(t *testing.T) {
+	t.Run("ShouldSkipRemovedSeat", func(t *testing.T) {
+		game := NewGame(3)
+		assert.NoError(t, game.RemovePlayer(1))
+		assert.False(t, game.InPlay(1))
+		assert.NoError(t, game.Draw())
+		assert.Equal(t, game.CurrentPlayer(), game.Player(2))
+		assert.True(t, game.View(0).Players[1].Removed)
+	})
+	t.Run("ShouldPassTurnOfRemovedPlayer", func(t *testing.T) {
+		game := NewGame(2)
+		assert.NoError(t, game.RemovePlayer(0))
+		assert.Equal(t, game.CurrentPlayer(), game.Player(1))
+	})
+	t.Run("ShouldCountOnlyPlayersInPlayForStalemate", func(t *testing.T) {
+		game := NewGame(3)
+		game.(*instance).tiles = nil
+		assert.NoError(t, game.RemovePlayer(2))
+		assert.NoError(t, game.Draw())
+		assert.NoError(t, game.Draw())
+		assert.True(t, game.IsStalemate())
+	})
+	t.Run("ShouldRejectMissingSeat", func(t *testing.T) {
+		game := NewGame(2)
+		assert.EqualError(t, game.RemovePlayer(2), fmt.Sprintf(constants.InvalidSeat, 3))
+	})
+}
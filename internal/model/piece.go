@@ -2,7 +2,10 @@ package model
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"lets-play-rummikub/internal/constants"
+	"strconv"
 )
 
 const (
@@ -26,8 +29,16 @@ var (
 		ColorRed:   "red",
 		ColorGreen: "green",
 	}
+	notationColors = map[Color]byte{
+		ColorBlack: 'K',
+		ColorBlue:  'B',
+		ColorRed:   'R',
+		ColorGreen: 'G',
+	}
 )
 
+const notationJoker = 'J'
+
 type (
 	Color uint8
 	Value uint8
@@ -40,6 +51,7 @@ type (
 		Value() Value
 		Color() Color
 		String() string
+		Notation() string
 		MarshalJSON() ([]byte, error)
 	}
 
@@ -54,23 +66,29 @@ type (
 )
 
 func (p *piece) MarshalJSON() ([]byte, error) {
-	var output any
+	return json.Marshal(p.output(0))
+}
+
+// output describes the piece for JSON, tagging it with its game ID when known.
+func (p *piece) output(id int) any {
 	if p.IsJoker() {
-		output = struct {
+		return struct {
+			ID    int  `json:"id,omitempty"`
 			Joker bool `json:"joker"`
 		}{
+			id,
 			true,
 		}
-	} else {
-		output = struct {
-			Value int    `json:"value"`
-			Color string `json:"color"`
-		}{
-			int(p.Value()),
-			stringColors[p.Color()],
-		}
 	}
-	return json.Marshal(output)
+	return struct {
+		ID    int    `json:"id,omitempty"`
+		Value int    `json:"value"`
+		Color string `json:"color"`
+	}{
+		id,
+		int(p.Value()),
+		stringColors[p.Color()],
+	}
 }
 
 func NewPiece(v Value, c Color) Piece {
@@ -148,3 +166,46 @@ func (p *piece) String() string {
 	}
 	return fmt.Sprintf("(\x1b[%dm%d\x1b[0m)", ansiColors[p.Color()], p.Value())
 }
+
+// ParseColor returns the color named as in the piece JSON, e.g. "red".
+func ParseColor(name string) (Color, bool) {
+	for color, colorName := range stringColors {
+		if colorName == name {
+			return color, true
+		}
+	}
+	return 0, false
+}
+
+// Notation returns the piece as its color letter and value, e.g. "R7" or "J".
+func (p *piece) Notation() string {
+	if !isValidPiece(p) {
+		return ""
+	}
+	if p.IsJoker() {
+		return string(notationJoker)
+	}
+	return fmt.Sprintf("%c%d", notationColors[p.Color()], p.Value())
+}
+
+// ParseNotation reads a piece written as a color letter (K, B, R or G)
+// followed by its value, or J for a joker.
+func ParseNotation(notation string) (Value, Color, error) {
+	if notation == string(notationJoker) {
+		return ValueJoker, ColorBlack, nil
+	}
+	if len(notation) < 2 {
+		return 0, 0, errors.New(constants.InvalidPieceNotation)
+	}
+	for color, letter := range notationColors {
+		if notation[0] != letter {
+			continue
+		}
+		value, err := strconv.Atoi(notation[1:])
+		if err != nil || value < 1 || value > 13 {
+			return 0, 0, errors.New(constants.InvalidPieceNotation)
+		}
+		return Value(value), color, nil
+	}
+	return 0, 0, errors.New(constants.InvalidPieceNotation)
+}
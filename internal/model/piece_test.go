@@ -2,6 +2,7 @@ package model
 
 import (
 	"github.com/stretchr/testify/assert"
+	"lets-play-rummikub/internal/constants"
 
 	"testing"
 )
@@ -160,3 +161,38 @@ func TestColor(t *testing.T) {
 		assert.Zero(t, value)
 	})
 }
+
+func TestNotation(t *testing.T) {
+	assert.Equal(t, NewPiece(7, ColorRed).Notation(), "R7")
+	assert.Equal(t, NewPiece(13, ColorBlack).Notation(), "K13")
+	assert.Equal(t, NewPiece(ValueJoker, ColorBlack).Notation(), "J")
+	assert.Equal(t, (&piece{value: 16, color: 5}).Notation(), "")
+}
+
+func TestParseNotation(t *testing.T) {
+	t.Run("ShouldParsePiece", func(t *testing.T) {
+		value, color, err := ParseNotation("B12")
+		assert.NoError(t, err)
+		assert.Equal(t, value, Value(12))
+		assert.Equal(t, color, ColorBlue)
+	})
+	t.Run("ShouldParseJoker", func(t *testing.T) {
+		value, _, err := ParseNotation("J")
+		assert.NoError(t, err)
+		assert.Equal(t, value, ValueJoker)
+	})
+	t.Run("ShouldReturnErrorOnInvalidNotation", func(t *testing.T) {
+		for _, notation := range []string{"", "R", "R0", "R14", "X5", "Rx"} {
+			_, _, err := ParseNotation(notation)
+			assert.EqualError(t, err, constants.InvalidPieceNotation, notation)
+		}
+	})
+}
+
+func TestParseColor(t *testing.T) {
+	color, ok := ParseColor("green")
+	assert.True(t, ok)
+	assert.Equal(t, color, ColorGreen)
+	_, ok = ParseColor("purple")
+	assert.False(t, ok)
+}
@@ -14,6 +14,7 @@ type (
 		Score() uint16
 		MarshalJSON() ([]byte, error)
 		Name() string
+		PrintRack()
 		SetName(string)
 		RemovePiece(pieces ...Piece)
 		HasPiece
@@ -73,7 +74,7 @@ func (p *player) DealPiece(piece Piece) {
 	p.rack = append(p.rack, piece)
 }
 
-func (p *player) printRack() {
+func (p *player) PrintRack() {
 	rack := &set{tiles: p.rack}
 	fmt.Printf("=== Rack ===\n%s=== Rack ===\n", rack.String())
 }
@@ -0,0 +1,44 @@
+package model
+
+import (
+	"errors"
+	"lets-play-rummikub/internal/constants"
+)
+
+const totalTiles = 106
+
+// Rules are the options a game is played with.
+type Rules struct {
+	HandSize    int `json:"handSize"`
+	InitialMeld int `json:"initialMeld"`
+}
+
+// DefaultRules are the rules from the rule book: 14 pieces each and an
+// initial meld worth at least 30 points.
+func DefaultRules() Rules {
+	return Rules{HandSize: 14, InitialMeld: 30}
+}
+
+// Validate checks the rules can be played with the given number of players.
+func (r Rules) Validate(players int) error {
+	maxHandSize := totalTiles / max(players, 1)
+	if r.HandSize < 1 || r.HandSize > maxHandSize {
+		return constants.Format(constants.InvalidHandSize, maxHandSize)
+	}
+	if r.InitialMeld < 0 {
+		return errors.New(constants.InvalidInitialMeld)
+	}
+	return nil
+}
+
+func (g *instance) Rules() Rules {
+	return g.rules
+}
+
+func (g *instance) SetRules(rules Rules) error {
+	if err := rules.Validate(len(g.players)); err != nil {
+		return err
+	}
+	g.rules = rules
+	return nil
+}
@@ -0,0 +1,32 @@
+package model
+
+import (
+	"fmt"
+	"lets-play-rummikub/internal/constants"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRules(t *testing.T) {
+	t.Run("ShouldValidateHandSize", func(t *testing.T) {
+		assert.NoError(t, DefaultRules().Validate(4))
+		assert.EqualError(t, Rules{HandSize: 0}.Validate(2), fmt.Sprintf(constants.InvalidHandSize, 53))
+		assert.EqualError(t, Rules{HandSize: 27}.Validate(4), fmt.Sprintf(constants.InvalidHandSize, 26))
+	})
+	t.Run("ShouldValidateInitialMeld", func(t *testing.T) {
+		assert.EqualError(t, Rules{HandSize: 14, InitialMeld: -1}.Validate(2), constants.InvalidInitialMeld)
+	})
+	t.Run("ShouldDealHandSize", func(t *testing.T) {
+		game := NewGame(2)
+		assert.NoError(t, game.SetRules(Rules{HandSize: 7, InitialMeld: 30}))
+		game.DealPieces()
+		assert.Equal(t, game.Player(0).RackLen(), 7)
+		assert.Equal(t, game.Player(1).RackLen(), 7)
+	})
+	t.Run("ShouldNotSetInvalidRules", func(t *testing.T) {
+		game := NewGame(2)
+		assert.Error(t, game.SetRules(Rules{}))
+		assert.Equal(t, game.Rules(), DefaultRules())
+	})
+}
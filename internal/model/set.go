@@ -182,7 +182,7 @@ func (s *set) cloneTiles() []Piece {
 
 func (s *set) Insert(piece Piece, index int) (Set, error) {
 	if index < 0 || index > len(s.tiles) {
-		return nil, errors.New(constants.IndexOutOfBounds(-1, len(s.tiles)+1))
+		return nil, constants.IndexOutOfBounds(-1, len(s.tiles)+1)
 	}
 	if len(s.tiles) != 0 && s.findIndex(piece) >= 0 {
 		return nil, errors.New(constants.InvalidPiece)
@@ -213,8 +213,8 @@ func (s *set) Split(index int) (Set, Set, error) {
 	if len(s.tiles) < 2 {
 		return nil, nil, errors.New(constants.TooFewPieces)
 	}
-	if index < 1 || len(s.tiles) - index <= 1  {
-		return nil, nil, errors.New(constants.IndexOutOfBounds(0, len(s.tiles)))
+	if index < 1 || index >= len(s.tiles) {
+		return nil, nil, constants.IndexOutOfBounds(0, len(s.tiles))
 	}
 	clone := s.cloneTiles()
 	return &set{tiles: clone[:index]}, &set{tiles: clone[index:]}, nil
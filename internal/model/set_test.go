@@ -245,14 +245,14 @@ func TestInsert(t *testing.T) {
 		set := new(set)
 		piece := NewPiece(ValueJoker, ColorBlack)
 		inserted, err := set.Insert(piece, -1)
-		assert.EqualError(t, err, constants.IndexOutOfBounds(-1, 1))
+		assert.EqualError(t, err, constants.IndexOutOfBounds(-1, 1).Error())
 		assert.Nil(t, inserted)
 	})
 	t.Run("ShouldReturnErrorOnIndexOutOfBounds", func(t *testing.T) {
 		set := &set{tiles: []Piece{NewPiece(Value(5), ColorBlack)}}
 		piece := NewPiece(ValueJoker, ColorBlack)
 		inserted, err := set.Insert(piece, 73)
-		assert.EqualError(t, err, constants.IndexOutOfBounds(-1, 2))
+		assert.EqualError(t, err, constants.IndexOutOfBounds(-1, 2).Error())
 		assert.Nil(t, inserted)
 	})
 	t.Run("ShouldReturnErrorOnExistingPiece", func(t *testing.T) {
@@ -346,12 +346,12 @@ func TestSplit(t *testing.T) {
 		original := &set{tiles: []Piece{NewPiece(Value(1), ColorBlack), NewPiece(Value(2), ColorBlack)}}
 		lower, upper, err := original.Split(-1)
 		assert.Len(t, original.tiles, 2)
-		assert.EqualError(t, err, constants.IndexOutOfBounds(0, 2))
+		assert.EqualError(t, err, constants.IndexOutOfBounds(0, 2).Error())
 		assert.Nil(t, lower)
 		assert.Nil(t, upper)
 		lower, upper, err = original.Split(2)
 		assert.Len(t, original.tiles, 2)
-		assert.EqualError(t, err, constants.IndexOutOfBounds(0, 2))
+		assert.EqualError(t, err, constants.IndexOutOfBounds(0, 2).Error())
 		assert.Nil(t, lower)
 		assert.Nil(t, upper)
 	})
@@ -0,0 +1,140 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"lets-play-rummikub/internal/constants"
+)
+
+type (
+	// gameState is everything needed to carry on a game after a restart.
+	// Pieces are stored by ID, so the pool keeps its order.
+	gameState struct {
+		Rules     Rules         `json:"rules"`
+		Pool      []int         `json:"pool"`
+		Board     [][]int       `json:"board"`
+		Loose     []int         `json:"loose"`
+		Players   []playerState `json:"players"`
+		Current   int           `json:"current"`
+		Melded    []bool        `json:"melded"`
+		Removed   []bool        `json:"removed,omitempty"`
+		Passes    int           `json:"passes"`
+		TurnStart [][]int       `json:"turnStart"`
+		TurnRack  int           `json:"turnRack"`
+	}
+
+	playerState struct {
+		Name string `json:"name"`
+		Rack []int  `json:"rack"`
+	}
+)
+
+func (g *instance) ids(pieces []Piece) []int {
+	ids := make([]int, len(pieces))
+	for i, p := range pieces {
+		ids[i] = g.PieceID(p)
+	}
+	return ids
+}
+
+func (g *instance) setIDs(sets []Set) [][]int {
+	ids := make([][]int, len(sets))
+	for i, s := range sets {
+		ids[i] = g.ids(s.(*set).tiles)
+	}
+	return ids
+}
+
+// MarshalState encodes the whole game, including hidden information, for
+// saving. Use View for anything sent to players.
+func (g *instance) MarshalState() ([]byte, error) {
+	players := make([]playerState, len(g.players))
+	for i, p := range g.players {
+		players[i] = playerState{p.Name(), g.ids(p.(*player).rack)}
+	}
+	return json.Marshal(gameState{
+		Rules:     g.rules,
+		Pool:      g.ids(g.tiles),
+		Board:     g.setIDs(g.board),
+		Loose:     g.ids(g.loose),
+		Players:   players,
+		Current:   g.currentPlayer,
+		Melded:    g.melded,
+		Removed:   g.removed,
+		Passes:    g.passes,
+		TurnStart: g.setIDs(g.turnStart),
+		TurnRack:  g.currentPlayerRackLen,
+	})
+}
+
+// RestoreGame rebuilds a game saved with MarshalState. Every piece must
+// appear exactly once.
+func RestoreGame(data []byte) (Game, error) {
+	var state gameState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, errors.New(constants.InvalidSavedGame)
+	}
+	if len(state.Players) < 1 || len(state.Melded) != len(state.Players) || state.Current < 0 || state.Current >= len(state.Players) {
+		return nil, errors.New(constants.InvalidSavedGame)
+	}
+	g := NewGame(uint(len(state.Players))).(*instance)
+	seen := make(map[int]bool)
+	pieces := func(ids []int) ([]Piece, error) {
+		pieces := make([]Piece, len(ids))
+		for i, id := range ids {
+			piece, err := g.PieceByID(id)
+			if err != nil || seen[id] {
+				return nil, errors.New(constants.InvalidSavedGame)
+			}
+			seen[id], pieces[i] = true, piece
+		}
+		return pieces, nil
+	}
+	sets := func(ids [][]int) ([]Set, error) {
+		sets := make([]Set, len(ids))
+		for i := range ids {
+			tiles, err := pieces(ids[i])
+			if err != nil {
+				return nil, err
+			}
+			sets[i] = &set{tiles}
+		}
+		return sets, nil
+	}
+	var err error
+	if g.tiles, err = pieces(state.Pool); err != nil {
+		return nil, err
+	}
+	if g.board, err = sets(state.Board); err != nil {
+		return nil, err
+	}
+	if g.loose, err = pieces(state.Loose); err != nil {
+		return nil, err
+	}
+	for i, saved := range state.Players {
+		rack, err := pieces(saved.Rack)
+		if err != nil {
+			return nil, err
+		}
+		g.players[i].SetName(saved.Name)
+		g.players[i].(*player).rack = rack
+	}
+	if len(seen) != len(g.pieces) {
+		return nil, errors.New(constants.InvalidSavedGame)
+	}
+	// Pieces in the turn start sets are also on the board, so they are
+	// looked up without checking for duplicates.
+	seen = make(map[int]bool)
+	if g.turnStart, err = sets(state.TurnStart); err != nil {
+		return nil, err
+	}
+	if err := state.Rules.Validate(len(state.Players)); err != nil {
+		return nil, err
+	}
+	g.rules, g.currentPlayer, g.melded, g.passes = state.Rules, state.Current, state.Melded, state.Passes
+	g.currentPlayerRackLen = state.TurnRack
+	if len(state.Removed) == len(state.Players) {
+		g.removed = state.Removed
+	}
+	return g, nil
+}
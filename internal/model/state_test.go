@@ -0,0 +1,54 @@
+package model
+
+import (
+	"encoding/json"
+	"lets-play-rummikub/internal/constants"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarshalState(t *testing.T) {
+	game := NewGame(2)
+	game.Shuffle()
+	game.DealPieces()
+	game.Player(1).SetName("Ana")
+	piece, _ := game.Player(0).Piece(0)
+	game.Player(0).RemovePiece(piece)
+	game.AddLoosePiece(piece)
+	state, err := game.MarshalState()
+	assert.NoError(t, err)
+
+	t.Run("ShouldRestoreGame", func(t *testing.T) {
+		restored, err := RestoreGame(state)
+		if assert.NoError(t, err) {
+			assert.Equal(t, restored.View(0), game.View(0))
+			assert.Equal(t, restored.View(1), game.View(1))
+			again, err := restored.MarshalState()
+			assert.NoError(t, err)
+			assert.JSONEq(t, string(again), string(state))
+		}
+	})
+	t.Run("ShouldKeepPoolOrder", func(t *testing.T) {
+		restored, _ := RestoreGame(state)
+		for i := 0; i < 5; i++ {
+			assert.Equal(t, restored.PieceID(restored.TakePiece()), game.PieceID(game.TakePiece()))
+		}
+	})
+	t.Run("ShouldRejectDuplicatePieces", func(t *testing.T) {
+		var saved gameState
+		assert.NoError(t, json.Unmarshal(state, &saved))
+		saved.Loose = append(saved.Loose, saved.Pool[0])
+		duplicated, _ := json.Marshal(saved)
+		_, err := RestoreGame(duplicated)
+		assert.EqualError(t, err, constants.InvalidSavedGame)
+	})
+	t.Run("ShouldRejectMissingPieces", func(t *testing.T) {
+		var saved gameState
+		assert.NoError(t, json.Unmarshal(state, &saved))
+		saved.Pool = saved.Pool[1:]
+		missing, _ := json.Marshal(saved)
+		_, err := RestoreGame(missing)
+		assert.EqualError(t, err, constants.InvalidSavedGame)
+	})
+}
@@ -0,0 +1,72 @@
+package model
+
+// SpectatorSeat is the seat spectators view the game from.
+const SpectatorSeat = -1
+
+type (
+	// View is everything the player in one seat is allowed to know about
+	// the game. Opponents are summarised by rack size and the pool by how
+	// many pieces are left, so neither their tiles nor the draw order leak.
+	View struct {
+		Seat    int         `json:"seat"`
+		Turn    int         `json:"turn"`
+		Pool    int         `json:"pool"`
+		Passes  int         `json:"passes"`
+		Board   []setOutput `json:"board"`
+		Loose   []any       `json:"piece"`
+		Rack    []any       `json:"rack"`
+		Players []SeatView  `json:"players"`
+		Racks   [][]any     `json:"racks,omitempty"`
+		Rules   Rules       `json:"rules"`
+	}
+
+	// SeatView is the public summary of one player. Removed players no
+	// longer take turns.
+	SeatView struct {
+		Name    string `json:"name"`
+		Rack    int    `json:"rack"`
+		Melded  bool   `json:"melded"`
+		Removed bool   `json:"removed,omitempty"`
+	}
+)
+
+// View projects the game for the given seat. Seats outside the game, such
+// as SpectatorSeat, see only public information and have an empty rack.
+func (g *instance) View(seat int) View {
+	board := make([]setOutput, len(g.board))
+	for i, s := range g.board {
+		board[i] = setOutput{g.identify(s.(*set).tiles)}
+	}
+	rack := make([]any, 0)
+	if seated := g.Player(seat); seated != nil {
+		rack = g.identify(seated.(*player).rack)
+	}
+	players := make([]SeatView, len(g.players))
+	for i, p := range g.players {
+		players[i] = SeatView{p.Name(), p.RackLen(), g.melded[i], g.removed[i]}
+	}
+	return View{
+		Seat:    seat,
+		Turn:    g.currentPlayer,
+		Pool:    len(g.tiles),
+		Passes:  g.passes,
+		Board:   board,
+		Loose:   g.identify(g.loose),
+		Rack:    rack,
+		Players: players,
+		Rules:   g.rules,
+	}
+}
+
+// SpectatorView projects the game for spectators, including every player's
+// rack when reveal is set.
+func (g *instance) SpectatorView(reveal bool) View {
+	view := g.View(SpectatorSeat)
+	if reveal {
+		view.Racks = make([][]any, len(g.players))
+		for i, p := range g.players {
+			view.Racks[i] = g.identify(p.(*player).rack)
+		}
+	}
+	return view
+}
@@ -0,0 +1,61 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestView(t *testing.T) {
+	game := NewGame(2)
+	game.DealPieces()
+	t.Run("ShouldShowOwnRack", func(t *testing.T) {
+		view := game.View(0)
+		assert.Equal(t, view.Seat, 0)
+		assert.Len(t, view.Rack, 14)
+		for i, output := range view.Rack {
+			tile, _ := game.Player(0).Piece(i)
+			assert.Equal(t, output, tile.(*piece).output(game.PieceID(tile)))
+		}
+	})
+	t.Run("ShouldSummariseOpponents", func(t *testing.T) {
+		game.Player(1).SetName("Ana")
+		view := game.View(0)
+		assert.Equal(t, view.Players, []SeatView{{"Player 1", 14, false, false}, {"Ana", 14, false, false}})
+		assert.Equal(t, view.Pool, 106-28)
+		assert.Equal(t, view.Turn, 0)
+	})
+	t.Run("ShouldNotLeakOpponentTiles", func(t *testing.T) {
+		output, err := json.Marshal(game.View(0))
+		assert.NoError(t, err)
+		for i := 0; i < game.Player(1).RackLen(); i++ {
+			tile, _ := game.Player(1).Piece(i)
+			hidden, _ := json.Marshal(tile.(*piece).output(game.PieceID(tile)))
+			assert.NotContains(t, string(output), string(hidden))
+		}
+	})
+	t.Run("ShouldShowNoRackOutsideSeats", func(t *testing.T) {
+		view := game.View(SpectatorSeat)
+		assert.Empty(t, view.Rack)
+		assert.Len(t, view.Players, 2)
+	})
+}
+
+func TestSpectatorView(t *testing.T) {
+	game := NewGame(2)
+	game.DealPieces()
+	t.Run("ShouldHideRacks", func(t *testing.T) {
+		view := game.SpectatorView(false)
+		assert.Equal(t, view.Seat, SpectatorSeat)
+		assert.Empty(t, view.Rack)
+		assert.Nil(t, view.Racks)
+	})
+	t.Run("ShouldRevealRacks", func(t *testing.T) {
+		view := game.SpectatorView(true)
+		assert.Empty(t, view.Rack)
+		if assert.Len(t, view.Racks, 2) {
+			assert.Equal(t, view.Racks[1], game.View(1).Rack)
+		}
+	})
+}
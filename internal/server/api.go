@@ -0,0 +1,303 @@
+package server
+
+import (
+	_ "embed"
+	"io"
+	"lets-play-rummikub/internal/constants"
+	"lets-play-rummikub/internal/locale"
+	"lets-play-rummikub/internal/model"
+	"net/http"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxRequestBody limits the size of HTTP API request bodies.
+const maxRequestBody = 4096
+
+// openAPI describes the HTTP API. It is served at /api/openapi.json.
+//
+//go:embed openapi.json
+var openAPI []byte
+
+type (
+	// GameOptions are sent to create a game over the HTTP API. Seats
+	// defaults to the lobby's room size and SpectatorDelay is a duration
+	// such as 30s.
+	GameOptions struct {
+		Seats          uint   `json:"seats"`
+		SpectatorDelay string `json:"spectatorDelay"`
+		RevealRacks    bool   `json:"revealRacks"`
+	}
+
+	// GameState is a room as seen from a seat over the HTTP API, or by a
+	// spectator, with seat -1, when no session token is sent. LastEvent is
+	// the number of the latest event, to poll for what happens next.
+	GameState struct {
+		Phase     Phase      `json:"phase"`
+		Paused    bool       `json:"paused"`
+		Seat      int        `json:"seat"`
+		Host      bool       `json:"host,omitempty"`
+		LastEvent uint64     `json:"lastEvent"`
+		View      model.View `json:"view"`
+	}
+
+	// MoveResult is returned for an applied command, with the notices it
+	// sent the player.
+	MoveResult struct {
+		Command string   `json:"command"`
+		Notices []string `json:"notices,omitempty"`
+	}
+
+	// APIError is returned by the HTTP API for a rejected request.
+	APIError struct {
+		Code    ErrorCode `json:"code"`
+		Message string    `json:"message"`
+	}
+)
+
+// apiStatus returns the HTTP status a rejection with the code is sent with.
+func apiStatus(code ErrorCode) int {
+	switch code {
+	case InvalidMessageCode, UnknownCommandCode, ParseErrorCode:
+		return http.StatusBadRequest
+	case UnauthorizedCode:
+		return http.StatusUnauthorized
+	case SpectatorCode, NotHostCode:
+		return http.StatusForbidden
+	case NotFoundCode:
+		return http.StatusNotFound
+	case NotYourTurnCode, NotReadyCode, RoomFullCode:
+		return http.StatusConflict
+	case RuleViolationCode:
+		return http.StatusUnprocessableEntity
+	}
+	return http.StatusInternalServerError
+}
+
+// bearerToken returns the session token sent in the Authorization header.
+func bearerToken(r *http.Request) string {
+	token, _ := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return strings.TrimSpace(token)
+}
+
+func requestLocale(r *http.Request) locale.Locale {
+	selected, _ := locale.Parse(r.Header.Get("Accept-Language"))
+	return selected
+}
+
+// writeError sends the error as an APIError, translated to the request's
+// locale.
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	code := errorCode(err)
+	writeJSON(w, apiStatus(code), APIError{code, requestLocale(r).Localize(err)})
+}
+
+// readBody decodes a JSON request body into value. An empty body leaves
+// value as it is.
+func readBody(w http.ResponseWriter, r *http.Request, value any) error {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestBody))
+	if err != nil {
+		return reject(InvalidMessageCode, constants.InvalidMessage)
+	}
+	if len(body) == 0 {
+		return nil
+	}
+	if err := decodeStrict(body, value); err != nil {
+		return &rejection{InvalidMessageCode, err}
+	}
+	return nil
+}
+
+// apiRoom returns the room named in the request path, sending an error when
+// there is none.
+func apiRoom(lobby *Lobby, w http.ResponseWriter, r *http.Request) (*Server, bool) {
+	room, ok := lobby.Room(r.PathValue("id"))
+	if !ok {
+		writeError(w, r, reject(NotFoundCode, constants.RoomNotFound))
+	}
+	return room, ok
+}
+
+// gameState returns the room as seen by the seat issued the token, or by a
+// spectator when the token is empty.
+func (s *Server) gameState(token string) (GameState, error) {
+	state := GameState{Phase: s.phase, Paused: s.paused, Seat: model.SpectatorSeat, LastEvent: s.lastEvent, View: s.spectated.view}
+	if token == "" {
+		return state, nil
+	}
+	seat := s.seatHolding(token)
+	if seat == model.SpectatorSeat {
+		return state, reject(UnauthorizedCode, constants.UnknownToken)
+	}
+	state.Seat, state.Host, state.View = seat, s.game.Player(seat) == s.host, s.game.View(seat)
+	return state, nil
+}
+
+// claimFreeSeat issues a session token for the first seat nobody holds.
+func (s *Server) claimFreeSeat() (SessionPayload, error) {
+	seat := s.claimSeat("")
+	if seat == model.SpectatorSeat {
+		return SessionPayload{Seat: seat}, reject(RoomFullCode, constants.RoomFull)
+	}
+	s.logger.Info("seat claimed over http", "seat", seat)
+	s.record(Event{Type: JoinedEvent, Seat: seat})
+	return SessionPayload{s.tokens[seat], seat, s.game.Player(seat) == s.host}, nil
+}
+
+// move handles a command for the seat issued the token as if it came from a
+// client connected to that seat, and returns the notices it sent.
+func (s *Server) move(token string, request CommandPayload, selected locale.Locale) (notices []string, err error) {
+	seat := s.seatHolding(token)
+	if seat == model.SpectatorSeat {
+		return nil, reject(UnauthorizedCode, constants.UnknownToken)
+	}
+	client := &Client{server: s, send: make(chan outgoing, s.settings.SendQueue), state: newStateSlot(), locale: selected, detached: true}
+	s.clients[client] = s.game.Player(seat)
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			s.logger.Error("panic handling move", "seat", seat, "panic", recovered, "stack", string(debug.Stack()))
+			err = reject(InternalErrorCode, constants.InternalError)
+		}
+		delete(s.clients, client)
+	}()
+	started := time.Now()
+	err = client.runCommand(request)
+	client.logCommand(request.Command, err, time.Since(started))
+	for len(client.send) > 0 {
+		if text, ok := (<-client.send).payload.(TextPayload); ok {
+			notices = append(notices, text.Text)
+		}
+	}
+	return notices, err
+}
+
+// ServeGames lists the rooms in the lobby.
+func ServeGames(lobby *Lobby, w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, lobby.Rooms())
+}
+
+// ServeCreateGame creates a room from the GameOptions in the request body
+// and returns it with the host's session token.
+func ServeCreateGame(lobby *Lobby, w http.ResponseWriter, r *http.Request) {
+	request := GameOptions{Seats: lobby.settings.RoomSize}
+	if err := readBody(w, r, &request); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	options := RoomOptions{Seats: request.Seats, RevealRacks: request.RevealRacks}
+	if request.SpectatorDelay != "" {
+		delay, err := time.ParseDuration(request.SpectatorDelay)
+		if err != nil {
+			writeError(w, r, reject(InvalidMessageCode, constants.InvalidSpectatorDelay))
+			return
+		}
+		options.SpectatorDelay = delay
+	}
+	room, err := lobby.CreateRoom(options)
+	if err != nil {
+		writeError(w, r, &rejection{InvalidMessageCode, err})
+		return
+	}
+	writeJSON(w, http.StatusCreated, CreatedRoom{room.Summary(), room.hostToken})
+}
+
+// ServeGame returns the room as seen from the seat whose session token is
+// sent as a bearer token, or by a spectator when none is sent.
+func ServeGame(lobby *Lobby, w http.ResponseWriter, r *http.Request) {
+	room, ok := apiRoom(lobby, w, r)
+	if !ok {
+		return
+	}
+	var state GameState
+	var err error
+	if callErr := room.call(func() { state, err = room.gameState(bearerToken(r)) }); callErr != nil {
+		err = callErr
+	}
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, state)
+}
+
+// ServeJoinGame takes the first free seat in the room and returns its
+// session token.
+func ServeJoinGame(lobby *Lobby, w http.ResponseWriter, r *http.Request) {
+	room, ok := apiRoom(lobby, w, r)
+	if !ok {
+		return
+	}
+	var session SessionPayload
+	var err error
+	if callErr := room.call(func() { session, err = room.claimFreeSeat() }); callErr != nil {
+		err = callErr
+	}
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, session)
+}
+
+// ServeMove handles the CommandPayload in the request body for the seat
+// whose session token is sent as a bearer token, the same as a command
+// sent over the websocket. Rejected commands are answered with their
+// NackPayload.
+func ServeMove(lobby *Lobby, w http.ResponseWriter, r *http.Request) {
+	room, ok := apiRoom(lobby, w, r)
+	if !ok {
+		return
+	}
+	token := bearerToken(r)
+	if token == "" {
+		writeError(w, r, reject(UnauthorizedCode, constants.MissingToken))
+		return
+	}
+	var request CommandPayload
+	err := readBody(w, r, &request)
+	if err == nil {
+		err = request.validate()
+	}
+	var notices []string
+	if err == nil {
+		if callErr := room.call(func() { notices, err = room.move(token, request, requestLocale(r)) }); callErr != nil {
+			err = callErr
+		}
+	}
+	if err != nil {
+		writeJSON(w, apiStatus(errorCode(err)), newNack(requestLocale(r), request.Command, err))
+		return
+	}
+	writeJSON(w, http.StatusOK, MoveResult{request.Command, notices})
+}
+
+// ServeEvents lists the events the room has kept, only those numbered after
+// the query value after when it is given.
+func ServeEvents(lobby *Lobby, w http.ResponseWriter, r *http.Request) {
+	room, ok := apiRoom(lobby, w, r)
+	if !ok {
+		return
+	}
+	var after uint64
+	if value := r.URL.Query().Get("after"); value != "" {
+		var err error
+		if after, err = strconv.ParseUint(value, 10, 64); err != nil {
+			writeError(w, r, reject(InvalidMessageCode, constants.InvalidEventCursor))
+			return
+		}
+	}
+	var events []Event
+	if err := room.call(func() { events = room.eventsAfter(after) }); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, events)
+}
+
+// ServeOpenAPI returns the OpenAPI description of the HTTP API.
+func ServeOpenAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(openAPI)
+}
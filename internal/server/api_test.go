@@ -0,0 +1,230 @@
+package server
+
+import (
+	"encoding/json"
+	"lets-play-rummikub/internal/constants"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type apiHandler func(*Lobby, http.ResponseWriter, *http.Request)
+
+// serveAPI sends the handler a request for the game with the session token
+// and body, decodes the response into value and returns its status.
+func serveAPI(t *testing.T, lobby *Lobby, handler apiHandler, target, id, token, body string, value any) int {
+	t.Helper()
+	method := http.MethodGet
+	if body != "" {
+		method = http.MethodPost
+	}
+	request := httptest.NewRequest(method, target, strings.NewReader(body))
+	request.SetPathValue("id", id)
+	if token != "" {
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+	recorder := httptest.NewRecorder()
+	handler(lobby, recorder, request)
+	if value != nil {
+		assert.NoError(t, json.NewDecoder(recorder.Body).Decode(value))
+	}
+	return recorder.Code
+}
+
+func createGame(t *testing.T, lobby *Lobby, options string) CreatedRoom {
+	t.Helper()
+	var created CreatedRoom
+	assert.Equal(t, serveAPI(t, lobby, ServeCreateGame, "/api/games", "", "", options, &created), http.StatusCreated)
+	return created
+}
+
+func TestServeGames(t *testing.T) {
+	t.Run("ShouldCreateAndListGames", func(t *testing.T) {
+		lobby := NewLobby(DefaultSettings())
+		created := createGame(t, lobby, `{"seats": 3, "spectatorDelay": "5m", "revealRacks": true}`)
+		assert.NotEmpty(t, created.Token)
+		assert.Equal(t, created.Seats, 3)
+		var games []RoomInfo
+		assert.Equal(t, serveAPI(t, lobby, ServeGames, "/api/games", "", "", "", &games), http.StatusOK)
+		assert.Equal(t, games, []RoomInfo{created.RoomInfo})
+		room, _ := lobby.Room(created.ID)
+		assert.True(t, room.options.RevealRacks)
+	})
+	t.Run("ShouldRejectInvalidOptions", func(t *testing.T) {
+		lobby := NewLobby(DefaultSettings())
+		for _, options := range []string{`{"seats": 9}`, `{"spectatorDelay": "soon"}`, `{"players": 2}`, `[`} {
+			var apiErr APIError
+			assert.Equal(t, serveAPI(t, lobby, ServeCreateGame, "/api/games", "", "", options, &apiErr), http.StatusBadRequest)
+			assert.Equal(t, apiErr.Code, InvalidMessageCode)
+		}
+		assert.Empty(t, lobby.Rooms())
+	})
+	t.Run("ShouldReportUnknownGames", func(t *testing.T) {
+		lobby := NewLobby(DefaultSettings())
+		var apiErr APIError
+		assert.Equal(t, serveAPI(t, lobby, ServeGame, "/api/games/42", "42", "", "", &apiErr), http.StatusNotFound)
+		assert.Equal(t, apiErr, APIError{NotFoundCode, constants.RoomNotFound})
+	})
+}
+
+func TestServeMove(t *testing.T) {
+	t.Run("ShouldPlayOverHTTP", func(t *testing.T) {
+		lobby := NewLobby(DefaultSettings())
+		created := createGame(t, lobby, `{"seats": 2}`)
+		var guest SessionPayload
+		assert.Equal(t, serveAPI(t, lobby, ServeJoinGame, "/", created.ID, "", "{}", &guest), http.StatusCreated)
+		assert.Equal(t, guest.Seat, 1)
+		assert.False(t, guest.Host)
+		var result MoveResult
+		assert.Equal(t, serveAPI(t, lobby, ServeMove, "/", created.ID, created.Token, `{"command": "start"}`, &result), http.StatusOK)
+		assert.Equal(t, result.Command, "start")
+		var state GameState
+		assert.Equal(t, serveAPI(t, lobby, ServeGame, "/", created.ID, created.Token, "", &state), http.StatusOK)
+		assert.Equal(t, state.Phase, InTurn)
+		assert.True(t, state.Host)
+		assert.Len(t, state.View.Rack, 14)
+		var nack NackPayload
+		assert.Equal(t, serveAPI(t, lobby, ServeMove, "/", created.ID, guest.Token, `{"command": "draw"}`, &nack), http.StatusConflict)
+		assert.Equal(t, nack, NackPayload{Command: "draw", Code: NotYourTurnCode, Message: constants.NotYourTurn})
+		assert.Equal(t, serveAPI(t, lobby, ServeMove, "/", created.ID, created.Token, `{"command": "draw"}`, nil), http.StatusOK)
+		assert.Equal(t, serveAPI(t, lobby, ServeGame, "/", created.ID, guest.Token, "", &state), http.StatusOK)
+		assert.Equal(t, state.Seat, 1)
+		assert.Equal(t, state.View.Turn, 1)
+	})
+	t.Run("ShouldRequireSessionToken", func(t *testing.T) {
+		lobby := NewLobby(DefaultSettings())
+		created := createGame(t, lobby, `{"seats": 1}`)
+		var nack NackPayload
+		assert.Equal(t, serveAPI(t, lobby, ServeMove, "/", created.ID, "", `{"command": "start"}`, &nack), http.StatusUnauthorized)
+		assert.Equal(t, nack.Message, constants.MissingToken)
+		assert.Equal(t, serveAPI(t, lobby, ServeMove, "/", created.ID, "guess", `{"command": "start"}`, &nack), http.StatusUnauthorized)
+		assert.Equal(t, nack.Message, constants.UnknownToken)
+		var apiErr APIError
+		assert.Equal(t, serveAPI(t, lobby, ServeGame, "/", created.ID, "guess", "", &apiErr), http.StatusUnauthorized)
+		assert.Equal(t, apiErr.Code, UnauthorizedCode)
+	})
+	t.Run("ShouldShowSpectatorViewWithoutToken", func(t *testing.T) {
+		lobby := NewLobby(DefaultSettings())
+		created := createGame(t, lobby, `{"seats": 1}`)
+		serveAPI(t, lobby, ServeMove, "/", created.ID, created.Token, `{"command": "start"}`, nil)
+		var state GameState
+		assert.Equal(t, serveAPI(t, lobby, ServeGame, "/", created.ID, "", "", &state), http.StatusOK)
+		assert.Equal(t, state.Seat, -1)
+		assert.Empty(t, state.View.Rack)
+		assert.Equal(t, state.View.Players[0].Rack, 14)
+	})
+	t.Run("ShouldReturnNackForRejectedMoves", func(t *testing.T) {
+		lobby := NewLobby(DefaultSettings())
+		created := createGame(t, lobby, `{"seats": 1}`)
+		serveAPI(t, lobby, ServeMove, "/", created.ID, created.Token, `{"command": "start"}`, nil)
+		var nack NackPayload
+		assert.Equal(t, serveAPI(t, lobby, ServeMove, "/", created.ID, created.Token, `{"command": "combine", "input": "r0 x"}`, &nack), http.StatusBadRequest)
+		assert.Equal(t, nack.Code, ParseErrorCode)
+		assert.NotZero(t, nack.Position)
+		assert.Equal(t, serveAPI(t, lobby, ServeMove, "/", created.ID, created.Token, `{"command": "fly"}`, &nack), http.StatusBadRequest)
+		assert.Equal(t, nack.Code, UnknownCommandCode)
+		assert.Equal(t, serveAPI(t, lobby, ServeMove, "/", created.ID, created.Token, `{"command": "undo"}`, &nack), http.StatusUnprocessableEntity)
+		assert.Equal(t, nack.Code, RuleViolationCode)
+		assert.Equal(t, serveAPI(t, lobby, ServeMove, "/", created.ID, created.Token, `{"command": "end", "extra": true}`, &nack), http.StatusBadRequest)
+		assert.Equal(t, nack.Code, InvalidMessageCode)
+	})
+	t.Run("ShouldNotCountDetachedClientsAsLeaving", func(t *testing.T) {
+		server := NewServer(1)
+		go server.Run()
+		server.call(func() {
+			client := &Client{server: server, send: make(chan outgoing, 1), state: newStateSlot(), detached: true}
+			server.clients[client] = server.game.Player(0)
+			connected, last := metrics.clients.Load(), server.lastEvent
+			server.disconnect(client)
+			assert.False(t, server.isConnected(client))
+			assert.Equal(t, metrics.clients.Load(), connected)
+			assert.Equal(t, server.lastEvent, last)
+		})
+	})
+	t.Run("ShouldReturnNotices", func(t *testing.T) {
+		lobby := NewLobby(DefaultSettings())
+		created := createGame(t, lobby, `{"seats": 1}`)
+		var result MoveResult
+		assert.Equal(t, serveAPI(t, lobby, ServeMove, "/", created.ID, created.Token, `{"command": "name", "input": "Ana"}`, &result), http.StatusOK)
+		assert.Equal(t, result.Notices, []string{"your name has been set to: Ana"})
+	})
+}
+
+func TestServeJoinGame(t *testing.T) {
+	t.Run("ShouldRejectFullRooms", func(t *testing.T) {
+		lobby := NewLobby(DefaultSettings())
+		created := createGame(t, lobby, `{"seats": 1}`)
+		var apiErr APIError
+		assert.Equal(t, serveAPI(t, lobby, ServeJoinGame, "/", created.ID, "", "{}", &apiErr), http.StatusConflict)
+		assert.Equal(t, apiErr, APIError{RoomFullCode, constants.RoomFull})
+	})
+	t.Run("ShouldLetHostKickHTTPPlayers", func(t *testing.T) {
+		lobby := NewLobby(DefaultSettings())
+		created := createGame(t, lobby, `{"seats": 2}`)
+		var guest SessionPayload
+		serveAPI(t, lobby, ServeJoinGame, "/", created.ID, "", "{}", &guest)
+		assert.Equal(t, serveAPI(t, lobby, ServeMove, "/", created.ID, created.Token, `{"command": "kick", "input": "Player 2"}`, nil), http.StatusOK)
+		var apiErr APIError
+		assert.Equal(t, serveAPI(t, lobby, ServeGame, "/", created.ID, guest.Token, "", &apiErr), http.StatusUnauthorized)
+		room, _ := lobby.Room(created.ID)
+		assert.Equal(t, room.Summary().Phase, Waiting)
+	})
+}
+
+func TestServeEvents(t *testing.T) {
+	t.Run("ShouldListEventsAfterSeq", func(t *testing.T) {
+		lobby := NewLobby(DefaultSettings())
+		created := createGame(t, lobby, `{"seats": 1}`)
+		serveAPI(t, lobby, ServeMove, "/", created.ID, created.Token, `{"command": "start"}`, nil)
+		var events []Event
+		assert.Equal(t, serveAPI(t, lobby, ServeEvents, "/", created.ID, "", "", &events), http.StatusOK)
+		types := make([]EventType, len(events))
+		for i, event := range events {
+			types[i] = event.Type
+			assert.Equal(t, event.Seq, uint64(i+1))
+		}
+		assert.Equal(t, types, []EventType{PhaseEvent, PhaseEvent, PhaseEvent, CommandEvent})
+		assert.Equal(t, *events[2].Phase, InTurn)
+		assert.Equal(t, events[3].Command, "start")
+		assert.Equal(t, serveAPI(t, lobby, ServeEvents, "/?after=3", created.ID, "", "", &events), http.StatusOK)
+		assert.Len(t, events, 1)
+		assert.Equal(t, serveAPI(t, lobby, ServeEvents, "/?after=4", created.ID, "", "", &events), http.StatusOK)
+		assert.Empty(t, events)
+		var apiErr APIError
+		assert.Equal(t, serveAPI(t, lobby, ServeEvents, "/?after=last", created.ID, "", "", &apiErr), http.StatusBadRequest)
+	})
+	t.Run("ShouldKeepLatestEvents", func(t *testing.T) {
+		server := NewServer(1)
+		for i := 0; i < maxEvents+10; i++ {
+			server.record(Event{Type: CommandEvent})
+		}
+		events := server.eventsAfter(0)
+		assert.Len(t, events, maxEvents)
+		assert.Equal(t, events[0].Seq, uint64(11))
+	})
+}
+
+func TestServeOpenAPI(t *testing.T) {
+	t.Run("ShouldDescribeEveryEndpoint", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		ServeOpenAPI(recorder, httptest.NewRequest(http.MethodGet, "/api/openapi.json", nil))
+		var spec struct {
+			OpenAPI string                    `json:"openapi"`
+			Paths   map[string]map[string]any `json:"paths"`
+		}
+		assert.NoError(t, json.NewDecoder(recorder.Body).Decode(&spec))
+		assert.Equal(t, spec.OpenAPI, "3.0.3")
+		for path, method := range map[string]string{
+			"/api/games":             "post",
+			"/api/games/{id}":        "get",
+			"/api/games/{id}/seats":  "post",
+			"/api/games/{id}/moves":  "post",
+			"/api/games/{id}/events": "get",
+		} {
+			assert.Contains(t, spec.Paths[path], method)
+		}
+	})
+}
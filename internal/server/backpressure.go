@@ -0,0 +1,78 @@
+package server
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+type (
+	// outgoing is a message queued for a client. It is encoded by the
+	// write pump so sequence numbers follow the order messages are sent.
+	outgoing struct {
+		messageType MessageType
+		id          string
+		payload     any
+	}
+
+	// stateSlot holds the latest state snapshot not yet sent to a client.
+	// A newer snapshot replaces an unsent one instead of queueing behind it,
+	// and is sent where the first one was queued.
+	stateSlot struct {
+		mu      sync.Mutex
+		pending *outgoing
+	}
+
+	// BackpressureStats counts how often slow clients were handled by each
+	// part of the send policy since the process started. They are written
+	// with the rest of the metrics by ServeMetrics.
+	BackpressureStats struct {
+		Coalesced    uint64 `json:"coalesced"`
+		Dropped      uint64 `json:"dropped"`
+		Disconnected uint64 `json:"disconnected"`
+	}
+)
+
+var backpressure struct {
+	coalesced    atomic.Uint64
+	dropped      atomic.Uint64
+	disconnected atomic.Uint64
+}
+
+// Backpressure returns the send policy counters.
+func Backpressure() BackpressureStats {
+	return BackpressureStats{
+		Coalesced:    backpressure.coalesced.Load(),
+		Dropped:      backpressure.dropped.Load(),
+		Disconnected: backpressure.disconnected.Load(),
+	}
+}
+
+func newStateSlot() *stateSlot {
+	return &stateSlot{}
+}
+
+// put stores the snapshot, counting any unsent one it replaces. It reports
+// whether the slot was empty, in which case the snapshot needs a place in
+// the send queue.
+func (s *stateSlot) put(message outgoing) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	empty := s.pending == nil
+	if !empty {
+		backpressure.coalesced.Add(1)
+	}
+	s.pending = &message
+	return empty
+}
+
+// take returns the latest snapshot, if one is waiting.
+func (s *stateSlot) take() (outgoing, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.pending == nil {
+		return outgoing{}, false
+	}
+	message := *s.pending
+	s.pending = nil
+	return message, true
+}
@@ -0,0 +1,101 @@
+package server
+
+import (
+	"lets-play-rummikub/internal/constants"
+	"lets-play-rummikub/internal/model"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	maxChatLength  = 200
+	chatScrollback = 50
+	spectatorName  = "Spectator"
+)
+
+type (
+	// chatRequest is a chat message from a client, with the ID of the
+	// envelope it arrived in.
+	chatRequest struct {
+		client  *Client
+		id      string
+		payload ChatPayload
+	}
+
+	// chatEntry is a public message kept for clients who join later.
+	// Messages from spectators are only ever shown to other spectators, so
+	// nothing they see after a delay can reach the players.
+	chatEntry struct {
+		payload    ChatPayload
+		spectators bool
+	}
+)
+
+// handleChat delivers a message to the room, or privately to the player
+// named in To, and keeps public messages as scrollback.
+func (s *Server) handleChat(request chatRequest) error {
+	text := strings.TrimSpace(request.payload.Text)
+	if text == "" {
+		return reject(InvalidMessageCode, constants.ChatEmpty)
+	}
+	if utf8.RuneCountInString(text) > maxChatLength {
+		return reject(InvalidMessageCode, constants.ChatTooLong, maxChatLength)
+	}
+	player, seated := s.clients[request.client]
+	message := ChatPayload{From: spectatorName, Text: text, Time: time.Now().UTC()}
+	if seated {
+		message.From = player.Name()
+	}
+	if request.payload.To != "" {
+		if !seated {
+			return reject(SpectatorCode, constants.SpectatorWhisper)
+		}
+		recipient := s.playerNamed(request.payload.To)
+		connections := s.clientsOf(recipient)
+		if len(connections) == 0 {
+			return reject(RuleViolationCode, constants.UnknownRecipient, request.payload.To)
+		}
+		message.To = recipient.Name()
+		for _, client := range connections {
+			client.write(ChatMessage, message)
+		}
+		if recipient != player {
+			request.client.write(ChatMessage, message)
+		}
+		return nil
+	}
+	s.chats = append(s.chats, chatEntry{message, !seated})
+	if len(s.chats) > chatScrollback {
+		s.chats = s.chats[len(s.chats)-chatScrollback:]
+	}
+	if seated {
+		for client := range s.clients {
+			client.write(ChatMessage, message)
+		}
+	}
+	for client := range s.spectators {
+		client.write(ChatMessage, message)
+	}
+	return nil
+}
+
+// clientsOf returns the clients connected to the player's seat.
+func (s *Server) clientsOf(player model.Player) []*Client {
+	var connected []*Client
+	for client, seated := range s.clients {
+		if player != nil && seated == player {
+			connected = append(connected, client)
+		}
+	}
+	return connected
+}
+
+// sendScrollback replays the kept messages a client is allowed to see.
+func (s *Server) sendScrollback(client *Client, spectator bool) {
+	for _, entry := range s.chats {
+		if spectator || !entry.spectators {
+			client.write(ChatMessage, entry.payload)
+		}
+	}
+}
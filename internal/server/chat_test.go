@@ -0,0 +1,103 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"lets-play-rummikub/internal/constants"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func receiveChat(t *testing.T, client *Client) ChatPayload {
+	t.Helper()
+	envelope, ok := nextMessage(t, client, ChatMessage)
+	assert.True(t, ok)
+	var chat ChatPayload
+	assert.NoError(t, json.Unmarshal(envelope.Payload, &chat))
+	return chat
+}
+
+func sendChat(t *testing.T, client *Client, chat ChatPayload) (Envelope, bool) {
+	t.Helper()
+	sendMessage(t, client, ChatMessage, chat)
+	return nextMessage(t, client, AckMessage, NackMessage)
+}
+
+func TestChat(t *testing.T) {
+	t.Run("ShouldBroadcastWithSenderAndTime", func(t *testing.T) {
+		server := NewServer(2)
+		go server.Run()
+		first, _ := joinSession(t, server, "")
+		second, _ := joinSession(t, server, "")
+		sendMessage(t, first, CommandMessage, CommandPayload{Command: "name", Input: "Ana"})
+		reply, _ := sendChat(t, first, ChatPayload{Text: " hello "})
+		assert.Equal(t, reply.Type, AckMessage)
+		assert.Equal(t, reply.ID, "1")
+		chat := receiveChat(t, second)
+		assert.Equal(t, chat.From, "Ana")
+		assert.Equal(t, chat.Text, "hello")
+		assert.False(t, chat.Time.IsZero())
+	})
+	t.Run("ShouldReplayScrollbackToLateJoiners", func(t *testing.T) {
+		server := NewServer(2)
+		go server.Run()
+		first, _ := joinSession(t, server, "")
+		for i := 0; i < chatScrollback+1; i++ {
+			sendChat(t, first, ChatPayload{Text: fmt.Sprint(i)})
+		}
+		late, _ := joinSession(t, server, "")
+		assert.Equal(t, receiveChat(t, late).Text, "1")
+	})
+	t.Run("ShouldSendPrivateMessagesOnlyToRecipient", func(t *testing.T) {
+		server := NewServer(3)
+		go server.Run()
+		first, _ := joinSession(t, server, "")
+		second, _ := joinSession(t, server, "")
+		third, _ := joinSession(t, server, "")
+		sendChat(t, first, ChatPayload{Text: "psst", To: "player 2"})
+		chat := receiveChat(t, second)
+		assert.Equal(t, chat.To, "Player 2")
+		sendChat(t, first, ChatPayload{Text: "everyone"})
+		assert.Equal(t, receiveChat(t, third).Text, "everyone")
+	})
+	t.Run("ShouldWhisperToPlayerHoldingName", func(t *testing.T) {
+		server := NewServer(3)
+		go server.Run()
+		first, _ := joinSession(t, server, "")
+		second, _ := joinSession(t, server, "")
+		third, _ := joinSession(t, server, "")
+		sendMessage(t, second, CommandMessage, CommandPayload{Command: "name", Input: "Ana"})
+		sendMessage(t, third, CommandMessage, CommandPayload{Command: "name", Input: "ana"})
+		receiveNack(t, third)
+		sendChat(t, first, ChatPayload{Text: "psst", To: "ANA"})
+		sendChat(t, first, ChatPayload{Text: "everyone"})
+		assert.Equal(t, receiveChat(t, second).Text, "psst")
+		assert.Equal(t, receiveChat(t, third).Text, "everyone")
+	})
+	t.Run("ShouldRejectInvalidMessages", func(t *testing.T) {
+		server := NewServer(1)
+		go server.Run()
+		client, _ := joinSession(t, server, "")
+		reply, _ := sendChat(t, client, ChatPayload{Text: strings.Repeat("a", maxChatLength+1)})
+		var nack NackPayload
+		assert.NoError(t, json.Unmarshal(reply.Payload, &nack))
+		assert.Equal(t, nack.Code, InvalidMessageCode)
+		assert.Equal(t, nack.Message, fmt.Sprintf(constants.ChatTooLong, maxChatLength))
+		reply, _ = sendChat(t, client, ChatPayload{Text: "hi", To: "nobody"})
+		assert.NoError(t, json.Unmarshal(reply.Payload, &nack))
+		assert.Equal(t, nack.Message, fmt.Sprintf(constants.UnknownRecipient, "nobody"))
+	})
+	t.Run("ShouldKeepSpectatorChatFromPlayers", func(t *testing.T) {
+		server := NewServer(2)
+		go server.Run()
+		first, _ := joinSession(t, server, "")
+		second, _ := joinSession(t, server, "")
+		spectator, _ := joinSession(t, server, "")
+		sendChat(t, spectator, ChatPayload{Text: "they have a joker"})
+		sendChat(t, first, ChatPayload{Text: "hi"})
+		assert.Equal(t, receiveChat(t, second).Text, "hi")
+		assert.Equal(t, receiveChat(t, spectator).Text, "hi")
+	})
+}
@@ -1,94 +1,272 @@
 package server
 
 import (
-	"bytes"
-	"encoding/json"
-	"fmt"
+	"lets-play-rummikub/internal/constants"
+	"lets-play-rummikub/internal/locale"
+	"lets-play-rummikub/internal/model"
 	"net/http"
+	"runtime/debug"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
 )
 
 type Client struct {
-	server  *Server
-	conn    *websocket.Conn
-	send    chan []byte
-	receive chan []byte
+	server   *Server
+	conn     *websocket.Conn
+	send     chan outgoing
+	state    *stateSlot
+	locale   locale.Locale
+	token    string
+	spectate bool
+	lagging  bool
+	seq      atomic.Uint64
+	// detached clients stand in for a seat while the room handles one HTTP
+	// request. They have no connection and were never counted as joined.
+	detached bool
 }
 
-const (
-	writeWait      = 10 * time.Second
-	pongWait       = 60 * time.Second
-	pingPeriod     = (pongWait * 9) / 10
-	maxMessageSize = 512
-)
-
-var (
-	newline  = []byte{'\n'}
-	space    = []byte{' '}
-	upgrader = websocket.Upgrader{
+// upgrader accepts websocket connections from the origins allowed in the
+// settings.
+func (s Settings) upgrader() *websocket.Upgrader {
+	upgrader := &websocket.Upgrader{
 		ReadBufferSize:  1024,
 		WriteBufferSize: 1024,
 	}
-)
+	if len(s.AllowedOrigins) > 0 {
+		upgrader.CheckOrigin = s.allowsOrigin
+	}
+	return upgrader
+}
+
+// allowsOrigin accepts requests without an Origin header, which do not come
+// from browsers, and those from an allowed origin. "*" allows any origin.
+func (s Settings) allowsOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	return origin == "" || slices.ContainsFunc(s.AllowedOrigins, func(allowed string) bool {
+		return allowed == "*" || strings.EqualFold(allowed, origin)
+	})
+}
 
 func (c *Client) readPump() {
+	settings := c.server.settings
 	defer func() {
-		c.server.unregister <- c
+		select {
+		case c.server.unregister <- c:
+		case <-c.server.done:
+		}
 		c.conn.Close()
 	}()
-	c.conn.SetReadLimit(maxMessageSize)
-	c.conn.SetReadDeadline(time.Now().Add(pongWait))
-	c.conn.SetPongHandler(func(string) error { c.conn.SetReadDeadline(time.Now().Add(pongWait)); return nil })
+	c.conn.SetReadLimit(settings.MaxMessageSize)
+	c.conn.SetReadDeadline(time.Now().Add(settings.PongWait))
+	c.conn.SetPongHandler(func(string) error { c.conn.SetReadDeadline(time.Now().Add(settings.PongWait)); return nil })
+	limiter := newRateLimiter(settings.RateLimit, settings.RateBurst)
+	dropped := 0
 	for {
 		_, message, err := c.conn.ReadMessage()
 		if err != nil {
 			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
-				fmt.Printf("error: %v", err)
+				c.server.logger.Warn("reading from client", "remote", c.conn.RemoteAddr().String(), "error", err)
 			}
 			break
 		}
-		message = bytes.TrimSpace(bytes.Replace(message, newline, space, -1))
-		var event Event
-		if err := json.Unmarshal(message, &event); err == nil {
-			c.handleCommand(event)
-		} else {
-			player := c.server.clients[c]
-			c.server.receive <- []byte(fmt.Sprintf("%s: %s", player.Name(), string(message)))
+		// Messages over the rate limit are dropped unanswered, and a
+		// client that keeps sending is disconnected.
+		if !limiter.allow(time.Now()) {
+			dropped++
+			if dropped > settings.RateBurst {
+				c.server.logger.Warn("disconnecting client over the rate limit", "remote", c.conn.RemoteAddr().String())
+				closing := websocket.FormatCloseMessage(websocket.ClosePolicyViolation, constants.RateLimited)
+				c.conn.WriteControl(websocket.CloseMessage, closing, time.Now().Add(settings.WriteWait))
+				break
+			}
+			continue
+		}
+		dropped = 0
+		select {
+		case c.server.receive <- ClientMessage{c, message}:
+		case <-c.server.done:
+			return
 		}
 	}
 }
 
+// handleMessage runs on the server's run loop, like everything else that
+// touches the game or the client's state. A message that panics disconnects
+// only the client that sent it.
+func (c *Client) handleMessage(message []byte) {
+	defer c.recoverPanic()
+	envelope, err := decodeEnvelope(message)
+	if err != nil {
+		c.write(ErrorMessage, TextPayload{c.locale.Localize(err)})
+		return
+	}
+	c.handleEnvelope(envelope)
+}
+
+func (c *Client) handleEnvelope(envelope Envelope) {
+	switch envelope.Type {
+	case CommandMessage:
+		var request CommandPayload
+		started := time.Now()
+		err := decodePayload(envelope.Payload, &request)
+		if err == nil {
+			err = c.runCommand(request)
+		}
+		c.logCommand(request.Command, err, time.Since(started))
+		if err != nil {
+			c.nack(envelope.ID, request.Command, err)
+			return
+		}
+		c.ack(envelope.ID, request.Command)
+	case ChatMessage:
+		var chat ChatPayload
+		if err := decodePayload(envelope.Payload, &chat); err != nil {
+			c.nack(envelope.ID, string(ChatMessage), err)
+			return
+		}
+		if err := c.server.handleChat(chatRequest{c, envelope.ID, chat}); err != nil {
+			c.nack(envelope.ID, string(ChatMessage), err)
+			return
+		}
+		c.ack(envelope.ID, string(ChatMessage))
+	default:
+		c.sendError(constants.InvalidMessage)
+	}
+}
+
+// runCommand handles a command, putting the table and the player's rack back
+// as they were if it panics.
+func (c *Client) runCommand(request CommandPayload) error {
+	game, player := c.server.game, c.server.clients[c]
+	undoGame := game.Clone()
+	var undoPlayer model.Player
+	if player != nil {
+		undoPlayer = player.Clone()
+	}
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			game.Restore(undoGame)
+			if player != nil {
+				player.Restore(undoPlayer)
+			}
+			panic(recovered)
+		}
+	}()
+	return c.handleCommand(request)
+}
+
+// logCommand records the outcome of a command in the metrics and the log,
+// and applied commands in the room's events.
+func (c *Client) logCommand(name string, err error, elapsed time.Duration) {
+	result := "ok"
+	if err != nil {
+		result = string(errorCode(err))
+	} else {
+		c.server.record(Event{Type: CommandEvent, Seat: c.server.seatOf(c), Command: name})
+	}
+	observeCommand(name, result, elapsed)
+	attrs := []any{"seat", c.server.seatOf(c), "command", name, "result", result, "duration", elapsed}
+	if err != nil {
+		attrs = append(attrs, "error", err.Error())
+	}
+	c.server.logger.Debug("command", attrs...)
+}
+
+// recoverPanic stops a panic while handling a client's message from taking
+// down the room, and disconnects the client.
+func (c *Client) recoverPanic() {
+	if recovered := recover(); recovered != nil {
+		c.server.logger.Error("panic handling message", "seat", c.server.seatOf(c), "panic", recovered, "stack", string(debug.Stack()))
+		c.sendError(constants.InternalError)
+		c.server.disconnect(c)
+	}
+}
+
+// write wraps the payload in the next envelope for this client and queues it.
+func (c *Client) write(messageType MessageType, payload any) {
+	c.reply(messageType, "", payload)
+}
+
+// reply is write for messages answering the client message with the given ID.
+// It never blocks the run loop. State snapshots replace any unsent snapshot,
+// which keeps its place in the queue, so acks and notices never arrive before
+// the state they follow. When the client's queue is full the message is
+// dropped and the client marked to be disconnected.
+func (c *Client) reply(messageType MessageType, id string, payload any) {
+	if c.lagging {
+		backpressure.dropped.Add(1)
+		return
+	}
+	message := outgoing{messageType, id, payload}
+	if messageType == StateMessage {
+		if !c.state.put(message) {
+			return
+		}
+		message = outgoing{messageType: StateMessage}
+	}
+	select {
+	case c.send <- message:
+		metrics.sendQueueDepth.observe(float64(len(c.send)))
+	default:
+		c.lagging = true
+		backpressure.dropped.Add(1)
+	}
+}
+
+// resolve returns the message to write for one taken from the send queue. A
+// state message without a payload marks the place of the snapshot in the
+// state slot and becomes the latest snapshot, or nothing if it was taken.
+func (c *Client) resolve(message outgoing) (outgoing, bool) {
+	if message.messageType != StateMessage || message.payload != nil {
+		return message, true
+	}
+	return c.state.take()
+}
+
+// encode wraps a queued message in the next envelope for this client.
+func (c *Client) encode(message outgoing) ([]byte, error) {
+	return encodeEnvelope(message.messageType, c.seq.Add(1), message.id, message.payload)
+}
+
+func (c *Client) writeMessage(message outgoing) error {
+	encoded, err := c.encode(message)
+	if err != nil {
+		// A payload that cannot be encoded is skipped, not fatal.
+		return nil
+	}
+	c.conn.SetWriteDeadline(time.Now().Add(c.server.settings.WriteWait))
+	return c.conn.WriteMessage(websocket.TextMessage, encoded)
+}
+
 func (c *Client) writePump() {
-	ticker := time.NewTicker(pingPeriod)
+	ticker := time.NewTicker(c.server.settings.pingPeriod())
 	defer func() {
 		ticker.Stop()
 		c.conn.Close()
+		c.server.writers.Done()
 	}()
 	for {
 		select {
 		case message, ok := <-c.send:
-			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
 			if !ok {
-				c.conn.WriteMessage(websocket.CloseMessage, []byte{})
-				return
-			}
-			w, err := c.conn.NextWriter(websocket.TextMessage)
-			if err != nil {
+				c.conn.SetWriteDeadline(time.Now().Add(c.server.settings.WriteWait))
+				c.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
 				return
 			}
-			w.Write(message)
-			n := len(c.send)
-			for i := 0; i < n; i++ {
-				w.Write(newline)
-				w.Write(<-c.send)
+			message, ok = c.resolve(message)
+			if !ok {
+				continue
 			}
-			if err := w.Close(); err != nil {
+			if err := c.writeMessage(message); err != nil {
 				return
 			}
 		case <-ticker.C:
-			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			c.conn.SetWriteDeadline(time.Now().Add(c.server.settings.WriteWait))
 			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
 				return
 			}
@@ -97,13 +275,27 @@ func (c *Client) writePump() {
 }
 
 func ServeWs(server *Server, w http.ResponseWriter, r *http.Request) {
-	conn, err := upgrader.Upgrade(w, r, nil)
+	conn, err := server.settings.upgrader().Upgrade(w, r, nil)
 	if err != nil {
-		fmt.Println(err)
+		server.logger.Warn("upgrading connection", "remote", r.RemoteAddr, "error", err)
+		return
+	}
+	selected, ok := locale.Parse(r.URL.Query().Get("locale"))
+	if !ok {
+		selected, _ = locale.Parse(r.Header.Get("Accept-Language"))
+	}
+	client := &Client{server: server, conn: conn, send: make(chan outgoing, server.settings.SendQueue), state: newStateSlot(), locale: selected, token: r.URL.Query().Get("token")}
+	client.spectate, _ = strconv.ParseBool(r.URL.Query().Get("spectate"))
+	// Count the writer before registering, so a shutdown that stops the room
+	// right after it joins still waits for its queue to be flushed.
+	client.server.writers.Add(1)
+	select {
+	case client.server.register <- client:
+	case <-client.server.done:
+		client.server.writers.Done()
+		conn.Close()
 		return
 	}
-	client := &Client{server: server, conn: conn, send: make(chan []byte, 256), receive: make(chan []byte, 256)}
-	client.server.register <- client
 	go client.writePump()
 	go client.readPump()
 }
@@ -0,0 +1,128 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"lets-play-rummikub/internal/constants"
+	"lets-play-rummikub/internal/model"
+	"log/slog"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// panickingGame panics when a player draws, standing in for a bug in the
+// game.
+type panickingGame struct {
+	model.Game
+}
+
+func (panickingGame) Draw() error {
+	panic("draw")
+}
+
+func sendRaw(client *Client, message string) {
+	client.server.receive <- ClientMessage{client, []byte(message)}
+}
+
+func TestValidation(t *testing.T) {
+	server := NewServer(1)
+	go server.Run()
+	client, _ := joinSession(t, server, "")
+	t.Run("ShouldRejectUnknownFields", func(t *testing.T) {
+		sendRaw(client, `{"v": 1, "type": "command", "id": "1", "payload": {"command": "name", "input": "Ana", "admin": true}}`)
+		nack := receiveNack(t, client)
+		assert.Equal(t, nack.Code, InvalidMessageCode)
+		assert.Equal(t, nack.Message, constants.InvalidMessage)
+	})
+	t.Run("ShouldRejectLongInput", func(t *testing.T) {
+		sendMessage(t, client, CommandMessage, CommandPayload{Command: "name", Input: strings.Repeat("a", maxInputLength+1)})
+		assert.Equal(t, receiveNack(t, client).Message, fmt.Sprintf(constants.InputTooLong, maxInputLength))
+	})
+	t.Run("ShouldRejectMissingCommand", func(t *testing.T) {
+		sendRaw(client, `{"v": 1, "type": "command", "id": "1"}`)
+		assert.Equal(t, receiveNack(t, client).Code, InvalidMessageCode)
+	})
+	t.Run("ShouldRejectLongRecipient", func(t *testing.T) {
+		sendMessage(t, client, ChatMessage, ChatPayload{To: strings.Repeat("a", maxInputLength+1), Text: "hi"})
+		assert.Equal(t, receiveNack(t, client).Code, InvalidMessageCode)
+	})
+	t.Run("ShouldRejectLongID", func(t *testing.T) {
+		sendRaw(client, fmt.Sprintf(`{"v": 1, "type": "chat", "id": %q, "payload": {"text": "hi"}}`, strings.Repeat("1", maxIDLength+1)))
+		envelope, _ := nextMessage(t, client, ErrorMessage)
+		var text TextPayload
+		assert.NoError(t, json.Unmarshal(envelope.Payload, &text))
+		assert.Equal(t, text.Text, constants.InvalidMessage)
+	})
+}
+
+func TestRecoverPanic(t *testing.T) {
+	t.Run("ShouldDisconnectOnlyTheClient", func(t *testing.T) {
+		server := NewServer(2)
+		server.game = panickingGame{server.game}
+		go server.Run()
+		first, _ := joinSession(t, server, "")
+		second, _ := joinSession(t, server, "")
+		sendMessage(t, first, CommandMessage, CommandPayload{Command: "start"})
+		sendMessage(t, first, CommandMessage, CommandPayload{Command: "draw"})
+		envelope, _ := nextMessage(t, first, ErrorMessage)
+		var text TextPayload
+		assert.NoError(t, json.Unmarshal(envelope.Payload, &text))
+		assert.Equal(t, text.Text, constants.InternalError)
+		_, open := nextMessage(t, first, SessionMessage)
+		assert.False(t, open)
+		assert.Equal(t, server.Summary().Players, 1)
+		sendMessage(t, second, ChatMessage, ChatPayload{Text: "still here"})
+		_, open = nextMessage(t, second, ChatMessage)
+		assert.True(t, open)
+	})
+}
+
+func TestLogCommand(t *testing.T) {
+	t.Run("ShouldLogRoomSeatCommandAndResult", func(t *testing.T) {
+		var output bytes.Buffer
+		server := NewServer(2)
+		server.logger = slog.New(slog.NewJSONHandler(&output, &slog.HandlerOptions{Level: slog.LevelDebug})).With("room", "7")
+		go server.Run()
+		joinSession(t, server, "")
+		client, _ := joinSession(t, server, "")
+		sendMessage(t, client, CommandMessage, CommandPayload{Command: "start"})
+		server.Summary()
+		var line map[string]any
+		for _, entry := range bytes.Split(output.Bytes(), []byte("\n")) {
+			if bytes.Contains(entry, []byte(`"msg":"command"`)) {
+				assert.NoError(t, json.Unmarshal(entry, &line))
+			}
+		}
+		assert.Equal(t, line["room"], "7")
+		assert.Equal(t, line["seat"], float64(1))
+		assert.Equal(t, line["command"], "start")
+		assert.Equal(t, line["result"], string(NotHostCode))
+		assert.Equal(t, line["error"], constants.NotHost)
+	})
+}
+
+func TestAllowedOrigins(t *testing.T) {
+	settings := DefaultSettings()
+	settings.AllowedOrigins = []string{"https://play.example"}
+	request := httptest.NewRequest("GET", "/ws/1", nil)
+	t.Run("ShouldAllowClientsWithoutOrigin", func(t *testing.T) {
+		assert.True(t, settings.allowsOrigin(request))
+	})
+	t.Run("ShouldAllowListedOrigin", func(t *testing.T) {
+		request.Header.Set("Origin", "https://play.example")
+		assert.True(t, settings.allowsOrigin(request))
+	})
+	t.Run("ShouldRejectOtherOrigins", func(t *testing.T) {
+		request.Header.Set("Origin", "https://evil.example")
+		assert.False(t, settings.allowsOrigin(request))
+		settings.AllowedOrigins = append(settings.AllowedOrigins, "*")
+		assert.True(t, settings.allowsOrigin(request))
+	})
+	t.Run("ShouldCheckSameOriginByDefault", func(t *testing.T) {
+		assert.Nil(t, DefaultSettings().upgrader().CheckOrigin)
+	})
+}
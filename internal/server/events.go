@@ -0,0 +1,55 @@
+package server
+
+import "time"
+
+// maxEvents is how many events a room keeps for clients polling the HTTP
+// API. Older events are dropped.
+const maxEvents = 256
+
+type EventType string
+
+const (
+	// JoinedEvent is recorded when a player takes a seat.
+	JoinedEvent EventType = "joined"
+	// LeftEvent is recorded when a player's connection leaves the room.
+	LeftEvent EventType = "left"
+	// PhaseEvent is recorded when the room moves to another phase.
+	PhaseEvent EventType = "phase"
+	// CommandEvent is recorded when a player's command is applied. The
+	// input is left out, as it names pieces in the player's rack.
+	CommandEvent EventType = "command"
+)
+
+// Event is something that happened in a room. Seq increases by one with
+// every event and starts again from 1 when the room is restored. Seat is
+// -1 for events that do not belong to a seat.
+type Event struct {
+	Seq     uint64    `json:"seq"`
+	Time    time.Time `json:"time"`
+	Type    EventType `json:"type"`
+	Seat    int       `json:"seat"`
+	Command string    `json:"command,omitempty"`
+	Phase   *Phase    `json:"phase,omitempty"`
+}
+
+// record numbers the event and keeps it, dropping the oldest once there
+// are more than maxEvents.
+func (s *Server) record(event Event) {
+	s.lastEvent++
+	event.Seq, event.Time = s.lastEvent, time.Now()
+	s.events = append(s.events, event)
+	if len(s.events) > maxEvents {
+		s.events = s.events[len(s.events)-maxEvents:]
+	}
+}
+
+// eventsAfter returns the kept events numbered after seq.
+func (s *Server) eventsAfter(seq uint64) []Event {
+	events := []Event{}
+	for _, event := range s.events {
+		if event.Seq > seq {
+			events = append(events, event)
+		}
+	}
+	return events
+}
@@ -0,0 +1,174 @@
+package server
+
+import (
+	"lets-play-rummikub/internal/command"
+	"lets-play-rummikub/internal/constants"
+	"lets-play-rummikub/internal/model"
+	"strings"
+)
+
+// hostCommands may only be sent by the host. The host is whoever holds the
+// first seat when the room is created, until they hand the role to another
+// player.
+var hostCommands = map[string]bool{
+	"rules":   true,
+	"start":   true,
+	"kick":    true,
+	"pause":   true,
+	"resume":  true,
+	"swap":    true,
+	"host":    true,
+	"abandon": true,
+}
+
+// announce sends a notice to every player and spectator in the room.
+func (s *Server) announce(format string, args ...any) {
+	for client := range s.clients {
+		client.sendNotice(format, args...)
+	}
+	for client := range s.spectators {
+		client.sendNotice(format, args...)
+	}
+}
+
+// sendSession tells a seated client its seat, the token that reclaims it,
+// and whether it holds the host role.
+func (s *Server) sendSession(client *Client) {
+	player := s.clients[client]
+	seat := s.seat(player)
+	client.write(SessionMessage, SessionPayload{s.tokens[seat], seat, player == s.host})
+}
+
+// setRules changes the hand size and initial meld before the game starts.
+func (s *Server) setRules(input string) error {
+	tokens, err := command.Parse("rules", input)
+	if err != nil {
+		return err
+	}
+	rules := model.Rules{HandSize: tokens[0].Number, InitialMeld: tokens[1].Number}
+	if err := s.game.SetRules(rules); err != nil {
+		return err
+	}
+	s.announce(constants.RulesChanged, rules.HandSize, rules.InitialMeld)
+	s.game.Notify()
+	return nil
+}
+
+// swapSeats exchanges two seats, numbered from 1, before the game starts.
+// Session tokens move with their players.
+func (s *Server) swapSeats(input string) error {
+	tokens, err := command.Parse("swap", input)
+	if err != nil {
+		return err
+	}
+	first, second := tokens[0].Number-1, tokens[1].Number-1
+	if err := s.game.SwapSeats(first, second); err != nil {
+		return err
+	}
+	s.tokens[first], s.tokens[second] = s.tokens[second], s.tokens[first]
+	for client := range s.clients {
+		s.sendSession(client)
+	}
+	s.game.Notify()
+	return nil
+}
+
+// kick disconnects the named player. Before the game starts their seat is
+// freed for someone else. Once it has started they are taken out of play
+// and their seat is kept from anyone else, so nobody takes over their rack.
+func (s *Server) kick(name string) error {
+	player := s.playerNamed(name)
+	if player == nil {
+		return reject(RuleViolationCode, constants.UnknownPlayer, name)
+	}
+	if player == s.host {
+		return reject(RuleViolationCode, constants.CannotKickHost)
+	}
+	seat := s.seat(player)
+	if !s.game.InPlay(seat) {
+		return reject(RuleViolationCode, constants.NotInPlay, player.Name())
+	}
+	for _, client := range s.clientsOf(player) {
+		client.sendNotice(constants.Kicked)
+		s.disconnect(client)
+	}
+	s.logger.Info("player kicked", "seat", seat)
+	s.announce(constants.PlayerKicked, player.Name())
+	if s.phase != InTurn {
+		s.tokens[seat] = ""
+		s.resetName(player, seat)
+		s.game.Notify()
+		return nil
+	}
+	s.tokens[seat] = newToken()
+	if s.game.CurrentPlayer() != player {
+		s.game.RemovePlayer(seat)
+		s.game.Notify()
+		return nil
+	}
+	for undoable := s.history.Pop(); undoable != nil; undoable = s.history.Pop() {
+		undoable.Undo()
+	}
+	s.game.RemovePlayer(seat)
+	s.endTurn()
+	return nil
+}
+
+// resetName gives a freed seat's player back a default name, so whoever
+// takes the seat next does not join as the kicked player. It is numbered
+// after the seat unless another player already has that name.
+func (s *Server) resetName(player model.Player, seat int) {
+	for i := seat; ; i++ {
+		if named := s.playerNamed(model.DefaultName(i)); named == nil || named == player {
+			player.SetName(model.DefaultName(i))
+			return
+		}
+	}
+}
+
+// playerNamed returns the seated player with the given name, ignoring case,
+// whether or not they are connected, or nil. Names are unique, as the name
+// command rejects one another player already has.
+func (s *Server) playerNamed(name string) model.Player {
+	for i := 0; i < s.game.TotalPlayers(); i++ {
+		if player := s.game.Player(i); strings.EqualFold(player.Name(), name) {
+			return player
+		}
+	}
+	return nil
+}
+
+// pause stops or resumes play. Turn commands are rejected while paused.
+func (s *Server) pause(paused bool) error {
+	if paused && s.paused {
+		return reject(NotReadyCode, constants.AlreadyPaused)
+	}
+	if !paused && !s.paused {
+		return reject(NotReadyCode, constants.NotPaused)
+	}
+	s.paused = paused
+	if paused {
+		s.announce(constants.GamePaused)
+	} else {
+		s.announce(constants.GameResumed)
+	}
+	return nil
+}
+
+// handOff gives the host role to the named player.
+func (s *Server) handOff(name string) error {
+	target := s.playerNamed(name)
+	if target == nil {
+		return reject(RuleViolationCode, constants.UnknownPlayer, name)
+	}
+	if !s.game.InPlay(s.seat(target)) {
+		return reject(RuleViolationCode, constants.NotInPlay, target.Name())
+	}
+	s.host = target
+	s.logger.Info("host changed", "seat", s.seat(s.host))
+	for client := range s.clients {
+		s.sendSession(client)
+	}
+	s.announce(constants.HostChanged, s.host.Name())
+	return nil
+}
@@ -0,0 +1,188 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"lets-play-rummikub/internal/constants"
+	"lets-play-rummikub/internal/model"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func receiveNack(t *testing.T, client *Client) NackPayload {
+	t.Helper()
+	envelope, ok := nextMessage(t, client, NackMessage)
+	assert.True(t, ok)
+	var nack NackPayload
+	assert.NoError(t, json.Unmarshal(envelope.Payload, &nack))
+	return nack
+}
+
+func receiveSession(t *testing.T, client *Client) SessionPayload {
+	t.Helper()
+	envelope, ok := nextMessage(t, client, SessionMessage)
+	assert.True(t, ok)
+	var session SessionPayload
+	assert.NoError(t, json.Unmarshal(envelope.Payload, &session))
+	return session
+}
+
+func TestHost(t *testing.T) {
+	t.Run("ShouldMakeFirstSeatHost", func(t *testing.T) {
+		server := NewServer(2)
+		go server.Run()
+		_, host := joinSession(t, server, "")
+		_, guest := joinSession(t, server, "")
+		assert.True(t, host.Host)
+		assert.False(t, guest.Host)
+	})
+	t.Run("ShouldKeepFirstSeatForCreator", func(t *testing.T) {
+		room, _ := NewLobby(DefaultSettings()).CreateRoom(RoomOptions{Seats: 2})
+		_, guest := joinSession(t, room, "")
+		_, creator := joinSession(t, room, room.hostToken)
+		assert.Equal(t, guest.Seat, 1)
+		assert.Equal(t, creator.Seat, 0)
+		assert.True(t, creator.Host)
+	})
+	t.Run("ShouldRejectHostCommandsFromGuests", func(t *testing.T) {
+		server := NewServer(2)
+		go server.Run()
+		joinSession(t, server, "")
+		guest, _ := joinSession(t, server, "")
+		for _, name := range []string{"rules", "start", "kick", "pause", "resume", "swap", "host", "abandon"} {
+			sendMessage(t, guest, CommandMessage, CommandPayload{Command: name})
+			nack := receiveNack(t, guest)
+			assert.Equal(t, nack.Code, NotHostCode)
+			assert.Equal(t, nack.Message, constants.NotHost)
+		}
+	})
+	t.Run("ShouldConfigureRulesBeforeStarting", func(t *testing.T) {
+		server := NewServer(1)
+		go server.Run()
+		host, _ := joinSession(t, server, "")
+		sendMessage(t, host, CommandMessage, CommandPayload{Command: "rules", Input: "10 25"})
+		server.Summary()
+		assert.Equal(t, receiveView(t, host).Rules.HandSize, 10)
+		sendMessage(t, host, CommandMessage, CommandPayload{Command: "rules", Input: "200 25"})
+		assert.Equal(t, receiveNack(t, host).Message, fmt.Sprintf(constants.InvalidHandSize, 106))
+		sendMessage(t, host, CommandMessage, CommandPayload{Command: "start"})
+		server.Summary()
+		assert.Len(t, receiveView(t, host).Rack, 10)
+		sendMessage(t, host, CommandMessage, CommandPayload{Command: "rules", Input: "14 30"})
+		assert.Equal(t, receiveNack(t, host).Message, constants.AlreadyStarted)
+	})
+	t.Run("ShouldSwapSeats", func(t *testing.T) {
+		server := NewServer(2)
+		go server.Run()
+		host, first := joinSession(t, server, "")
+		guest, second := joinSession(t, server, "")
+		sendMessage(t, host, CommandMessage, CommandPayload{Command: "swap", Input: "1 2"})
+		swapped := receiveSession(t, host)
+		assert.Equal(t, swapped, SessionPayload{first.Token, 1, true})
+		assert.Equal(t, receiveSession(t, guest), SessionPayload{Token: second.Token, Seat: 0})
+		sendMessage(t, host, CommandMessage, CommandPayload{Command: "swap", Input: "1 3"})
+		assert.Equal(t, receiveNack(t, host).Message, fmt.Sprintf(constants.InvalidSeat, 3))
+	})
+	t.Run("ShouldKickPlayer", func(t *testing.T) {
+		server := NewServer(2)
+		go server.Run()
+		host, _ := joinSession(t, server, "")
+		guest, session := joinSession(t, server, "")
+		sendMessage(t, host, CommandMessage, CommandPayload{Command: "kick", Input: "player 1"})
+		assert.Equal(t, receiveNack(t, host).Message, constants.CannotKickHost)
+		sendMessage(t, host, CommandMessage, CommandPayload{Command: "kick", Input: "Player 2"})
+		nextMessage(t, host, AckMessage)
+		_, open := nextMessage(t, guest, SessionMessage)
+		assert.False(t, open)
+		_, reclaimed := joinSession(t, server, session.Token)
+		assert.NotEqual(t, reclaimed.Token, session.Token)
+		assert.Equal(t, reclaimed.Seat, 1)
+	})
+	t.Run("ShouldResetNameOfKickedSeat", func(t *testing.T) {
+		server := NewServer(2)
+		go server.Run()
+		host, _ := joinSession(t, server, "")
+		guest, _ := joinSession(t, server, "")
+		sendMessage(t, guest, CommandMessage, CommandPayload{Command: "name", Input: "Bea"})
+		nextMessage(t, guest, AckMessage)
+		sendMessage(t, host, CommandMessage, CommandPayload{Command: "kick", Input: "Bea"})
+		nextMessage(t, host, AckMessage)
+		_, rejoined := joinSession(t, server, "")
+		assert.Equal(t, rejoined.Seat, 1)
+		assert.Equal(t, server.game.View(0).Players[1].Name, "Player 2")
+		sendMessage(t, host, CommandMessage, CommandPayload{Command: "kick", Input: "Bea"})
+		assert.Equal(t, receiveNack(t, host).Message, fmt.Sprintf(constants.UnknownPlayer, "Bea"))
+	})
+	t.Run("ShouldNotResetToTakenName", func(t *testing.T) {
+		server := NewServer(2)
+		go server.Run()
+		host, _ := joinSession(t, server, "")
+		joinSession(t, server, "")
+		sendMessage(t, host, CommandMessage, CommandPayload{Command: "swap", Input: "1 2"})
+		sendMessage(t, host, CommandMessage, CommandPayload{Command: "kick", Input: "Player 2"})
+		nextMessage(t, host, AckMessage)
+		server.Summary()
+		assert.Equal(t, server.game.View(0).Players[0].Name, "Player 2")
+		assert.Equal(t, server.game.View(0).Players[1].Name, "Player 1")
+	})
+	t.Run("ShouldKeepKickedSeatDuringGame", func(t *testing.T) {
+		server := NewServer(3)
+		go server.Run()
+		host, _ := joinSession(t, server, "")
+		guest, session := joinSession(t, server, "")
+		joinSession(t, server, "")
+		sendMessage(t, host, CommandMessage, CommandPayload{Command: "start"})
+		sendMessage(t, host, CommandMessage, CommandPayload{Command: "draw"})
+		server.Summary()
+		sendMessage(t, host, CommandMessage, CommandPayload{Command: "kick", Input: "Player 2"})
+		server.Summary()
+		_, open := nextMessage(t, guest, SessionMessage)
+		assert.False(t, open)
+		assert.Equal(t, receiveView(t, host).Turn, 2)
+		assert.Equal(t, server.Summary().Phase, InTurn)
+		_, rejoined := joinSession(t, server, session.Token)
+		assert.Equal(t, rejoined.Seat, model.SpectatorSeat)
+		sendMessage(t, host, CommandMessage, CommandPayload{Command: "kick", Input: "Player 2"})
+		assert.Equal(t, receiveNack(t, host).Message, fmt.Sprintf(constants.NotInPlay, "Player 2"))
+		sendMessage(t, host, CommandMessage, CommandPayload{Command: "host", Input: "Player 2"})
+		assert.Equal(t, receiveNack(t, host).Message, fmt.Sprintf(constants.NotInPlay, "Player 2"))
+	})
+	t.Run("ShouldRejectTakenName", func(t *testing.T) {
+		server := NewServer(2)
+		go server.Run()
+		host, _ := joinSession(t, server, "")
+		guest, _ := joinSession(t, server, "")
+		sendMessage(t, guest, CommandMessage, CommandPayload{Command: "name", Input: "player 1"})
+		assert.Equal(t, receiveNack(t, guest).Message, fmt.Sprintf(constants.NameTaken, "player 1"))
+		sendMessage(t, host, CommandMessage, CommandPayload{Command: "name", Input: "PLAYER 1"})
+		nextMessage(t, host, AckMessage)
+	})
+	t.Run("ShouldPauseTurns", func(t *testing.T) {
+		server := NewServer(1)
+		go server.Run()
+		host, _ := joinSession(t, server, "")
+		sendMessage(t, host, CommandMessage, CommandPayload{Command: "pause"})
+		assert.Equal(t, receiveNack(t, host).Message, constants.GameNotStarted)
+		sendMessage(t, host, CommandMessage, CommandPayload{Command: "start"})
+		sendMessage(t, host, CommandMessage, CommandPayload{Command: "pause"})
+		sendMessage(t, host, CommandMessage, CommandPayload{Command: "draw"})
+		nack := receiveNack(t, host)
+		assert.Equal(t, nack.Code, NotReadyCode)
+		assert.Equal(t, nack.Message, constants.GamePaused)
+		sendMessage(t, host, CommandMessage, CommandPayload{Command: "resume"})
+		sendMessage(t, host, CommandMessage, CommandPayload{Command: "resume"})
+		assert.Equal(t, receiveNack(t, host).Message, constants.NotPaused)
+	})
+	t.Run("ShouldHandOffHost", func(t *testing.T) {
+		server := NewServer(2)
+		go server.Run()
+		host, _ := joinSession(t, server, "")
+		guest, _ := joinSession(t, server, "")
+		sendMessage(t, host, CommandMessage, CommandPayload{Command: "host", Input: "Player 2"})
+		assert.False(t, receiveSession(t, host).Host)
+		assert.True(t, receiveSession(t, guest).Host)
+		sendMessage(t, host, CommandMessage, CommandPayload{Command: "start"})
+		assert.Equal(t, receiveNack(t, host).Code, NotHostCode)
+	})
+}
@@ -0,0 +1,162 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"lets-play-rummikub/internal/constants"
+	"lets-play-rummikub/internal/model"
+	"slices"
+	"time"
+)
+
+// Phase is where a room is in the life of its game. Rooms wait for every
+// seat to be taken, are ready once they are, deal when the host starts and
+// then play turns until the game is finished or the host abandons it.
+type Phase uint8
+
+const (
+	Waiting Phase = iota
+	Ready
+	Dealing
+	InTurn
+	Finished
+	Abandoned
+)
+
+var phaseNames = []string{"waiting", "ready", "dealing", "in_turn", "finished", "abandoned"}
+
+// transitions lists the phases each phase may move to.
+var transitions = map[Phase][]Phase{
+	Waiting:   {Ready, Abandoned},
+	Ready:     {Waiting, Dealing, Abandoned},
+	Dealing:   {InTurn},
+	InTurn:    {Finished, Abandoned},
+	Finished:  {},
+	Abandoned: {},
+}
+
+var (
+	setupCommands = []string{"name", "locale", "rules", "swap", "kick", "host", "abandon"}
+	playCommands  = []string{"combine", "insert", "remove", "move", "split", "splitinsert", "rearrange", "undo", "end", "draw"}
+
+	// phaseCommands lists the commands allowed in each phase.
+	phaseCommands = map[Phase][]string{
+		Waiting:   setupCommands,
+		Ready:     append(slices.Clone(setupCommands), "start"),
+		Dealing:   {"locale"},
+		InTurn:    append(slices.Clone(playCommands), "name", "locale", "kick", "host", "pause", "resume", "abandon"),
+		Finished:  {"locale"},
+		Abandoned: {"locale"},
+	}
+
+	// phaseErrors explains why a command is not allowed in each phase.
+	phaseErrors = map[Phase]string{
+		Waiting:   constants.NotEnoughPlayersToStart,
+		Ready:     constants.GameNotStarted,
+		Dealing:   constants.GameDealing,
+		InTurn:    constants.AlreadyStarted,
+		Finished:  constants.GameFinished,
+		Abandoned: constants.GameAbandoned,
+	}
+)
+
+func (p Phase) String() string {
+	if int(p) < len(phaseNames) {
+		return phaseNames[p]
+	}
+	return fmt.Sprintf("phase(%d)", p)
+}
+
+func (p Phase) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+func (p *Phase) UnmarshalText(text []byte) error {
+	index := slices.Index(phaseNames, string(text))
+	if index < 0 {
+		return errors.New(constants.InvalidSavedGame)
+	}
+	*p = Phase(index)
+	return nil
+}
+
+// allows reports whether the command may be sent in this phase.
+func (p Phase) allows(command string) bool {
+	return slices.Contains(phaseCommands[p], command)
+}
+
+// transition moves the room to the given phase, telling every client, and
+// runs what happens on entering it: dealing shuffles and deals the pieces
+// and then starts the first turn.
+func (s *Server) transition(to Phase) error {
+	from := s.phase
+	if !slices.Contains(transitions[from], to) {
+		return reject(NotReadyCode, phaseErrors[from])
+	}
+	s.phase = to
+	s.logger.Info("phase changed", "from", from, "to", to)
+	s.record(Event{Type: PhaseEvent, Seat: model.SpectatorSeat, Phase: &to})
+	for client := range s.clients {
+		client.write(PhaseMessage, PhasePayload{to, &from})
+	}
+	for client := range s.spectators {
+		client.write(PhaseMessage, PhasePayload{to, &from})
+	}
+	switch to {
+	case Dealing:
+		s.game.Shuffle()
+		s.game.DealPieces()
+		return s.transition(InTurn)
+	case InTurn:
+		s.turnStarted = time.Now()
+		s.game.Notify(constants.Format(constants.PlayerTurn, s.game.CurrentPlayer().Name()))
+	case Finished, Abandoned:
+		s.paused = false
+		if to == Abandoned && from == InTurn {
+			metrics.gamesFinished.inc("abandoned")
+		}
+	}
+	return nil
+}
+
+// checkSeats moves a room that has not started between Waiting and Ready as
+// seats are taken and freed. A seat is taken while its session token is
+// issued, whether its player is connected by websocket, plays over HTTP or
+// has dropped and may reconnect.
+func (s *Server) checkSeats() {
+	full := !slices.Contains(s.tokens, "")
+	if s.phase == Waiting && full {
+		s.transition(Ready)
+	}
+	if s.phase == Ready && !full {
+		s.transition(Waiting)
+	}
+}
+
+// endTurn records how long the turn took, then finishes the game once a
+// player has emptied their rack or every player has passed with the pool
+// empty.
+func (s *Server) endTurn() {
+	metrics.turnDuration.observe(time.Since(s.turnStarted).Seconds())
+	s.turnStarted = time.Now()
+	switch {
+	case s.game.IsGameOver():
+		s.announce(constants.PlayerWon, s.winner().Name())
+		metrics.gamesFinished.inc("won")
+		s.transition(Finished)
+	case s.game.IsStalemate():
+		s.announce(constants.Stalemate)
+		metrics.gamesFinished.inc("stalemate")
+		s.transition(Finished)
+	}
+}
+
+// winner returns the player who went out, or nil.
+func (s *Server) winner() model.Player {
+	for i := 0; i < s.game.TotalPlayers(); i++ {
+		if player := s.game.Player(i); player.RackLen() == 0 {
+			return player
+		}
+	}
+	return nil
+}
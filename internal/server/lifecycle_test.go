@@ -0,0 +1,110 @@
+package server
+
+import (
+	"encoding/json"
+	"lets-play-rummikub/internal/constants"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func receivePhase(t *testing.T, client *Client) PhasePayload {
+	t.Helper()
+	envelope, ok := nextMessage(t, client, PhaseMessage)
+	assert.True(t, ok)
+	var phase PhasePayload
+	assert.NoError(t, json.Unmarshal(envelope.Payload, &phase))
+	return phase
+}
+
+func TestLifecycle(t *testing.T) {
+	t.Run("ShouldWaitForEverySeat", func(t *testing.T) {
+		server := NewServer(2)
+		go server.Run()
+		first, _ := joinSession(t, server, "")
+		assert.Equal(t, receivePhase(t, first).Phase, Waiting)
+		second, _ := joinSession(t, server, "")
+		assert.Equal(t, server.Summary().Phase, Ready)
+		assert.Equal(t, receivePhase(t, first).Phase, Ready)
+		server.unregister <- second
+		assert.Equal(t, server.Summary().Phase, Ready)
+		sendMessage(t, first, CommandMessage, CommandPayload{Command: "kick", Input: "Player 2"})
+		assert.Equal(t, server.Summary().Phase, Waiting)
+		sendMessage(t, first, CommandMessage, CommandPayload{Command: "start"})
+		nack := receiveNack(t, first)
+		assert.Equal(t, nack.Code, NotReadyCode)
+		assert.Equal(t, nack.Message, constants.NotEnoughPlayersToStart)
+	})
+	t.Run("ShouldRejectMovesBeforeStart", func(t *testing.T) {
+		server := NewServer(1)
+		go server.Run()
+		client, _ := joinSession(t, server, "")
+		sendMessage(t, client, CommandMessage, CommandPayload{Command: "combine", Input: "r0 r1 r2"})
+		nack := receiveNack(t, client)
+		assert.Equal(t, nack.Code, NotReadyCode)
+		assert.Equal(t, nack.Message, constants.GameNotStarted)
+	})
+	t.Run("ShouldDealOnStart", func(t *testing.T) {
+		server := NewServer(1)
+		go server.Run()
+		client, _ := joinSession(t, server, "")
+		assert.Equal(t, receivePhase(t, client).Phase, Waiting)
+		assert.Equal(t, receivePhase(t, client).Phase, Ready)
+		sendMessage(t, client, CommandMessage, CommandPayload{Command: "start"})
+		ready, dealing := Ready, Dealing
+		assert.Equal(t, receivePhase(t, client), PhasePayload{Dealing, &ready})
+		assert.Equal(t, receivePhase(t, client), PhasePayload{InTurn, &dealing})
+		server.Summary()
+		assert.Equal(t, server.game.Player(0).RackLen(), 14)
+		sendMessage(t, client, CommandMessage, CommandPayload{Command: "start"})
+		assert.Equal(t, receiveNack(t, client).Message, constants.AlreadyStarted)
+	})
+	t.Run("ShouldFinishOnStalemate", func(t *testing.T) {
+		server := NewServer(1)
+		go server.Run()
+		client, _ := joinSession(t, server, "")
+		sendMessage(t, client, CommandMessage, CommandPayload{Command: "rules", Input: "106 30"})
+		sendMessage(t, client, CommandMessage, CommandPayload{Command: "start"})
+		sendMessage(t, client, CommandMessage, CommandPayload{Command: "draw"})
+		assert.Equal(t, server.Summary().Phase, Finished)
+		sendMessage(t, client, CommandMessage, CommandPayload{Command: "draw"})
+		assert.Equal(t, receiveNack(t, client).Message, constants.GameFinished)
+	})
+	t.Run("ShouldNotFinishOnJokerRack", func(t *testing.T) {
+		server := NewServer(2)
+		go server.Run()
+		host, _ := joinSession(t, server, "")
+		joinSession(t, server, "")
+		sendMessage(t, host, CommandMessage, CommandPayload{Command: "start"})
+		server.call(func() {
+			guest := server.game.Player(1)
+			for guest.RackLen() > 0 {
+				piece, _ := guest.Piece(0)
+				guest.RemovePiece(piece)
+			}
+			joker, _ := server.game.PieceByID(106)
+			guest.DealPiece(joker)
+		})
+		sendMessage(t, host, CommandMessage, CommandPayload{Command: "draw"})
+		assert.Equal(t, server.Summary().Phase, InTurn)
+	})
+	t.Run("ShouldAbandonGame", func(t *testing.T) {
+		server := NewServer(1)
+		go server.Run()
+		client, _ := joinSession(t, server, "")
+		sendMessage(t, client, CommandMessage, CommandPayload{Command: "start"})
+		sendMessage(t, client, CommandMessage, CommandPayload{Command: "abandon"})
+		assert.Equal(t, server.Summary().Phase, Abandoned)
+		sendMessage(t, client, CommandMessage, CommandPayload{Command: "draw"})
+		assert.Equal(t, receiveNack(t, client).Message, constants.GameAbandoned)
+	})
+	t.Run("ShouldEncodePhaseByName", func(t *testing.T) {
+		encoded, err := json.Marshal(InTurn)
+		assert.NoError(t, err)
+		assert.JSONEq(t, string(encoded), `"in_turn"`)
+		var decoded Phase
+		assert.NoError(t, json.Unmarshal(encoded, &decoded))
+		assert.Equal(t, decoded, InTurn)
+		assert.Error(t, json.Unmarshal([]byte(`"playing"`), &decoded))
+	})
+}
@@ -0,0 +1,229 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"lets-play-rummikub/internal/constants"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	minSeats = 1
+	maxSeats = 4
+)
+
+type (
+	// Lobby holds every room on the server. Each room is a Server with its
+	// own game, history and run loop.
+	Lobby struct {
+		mu       sync.Mutex
+		rooms    map[string]*Server
+		lastID   int
+		settings Settings
+	}
+
+	// RoomOptions are chosen by whoever creates a room. Spectators see
+	// the game SpectatorDelay behind the players, with every rack shown
+	// when RevealRacks is set.
+	RoomOptions struct {
+		Seats          uint          `json:"seats"`
+		SpectatorDelay time.Duration `json:"spectatorDelay"`
+		RevealRacks    bool          `json:"revealRacks"`
+	}
+
+	// CreatedRoom is returned to whoever creates a room, with the session
+	// token for the first seat, which holds the host role.
+	CreatedRoom struct {
+		RoomInfo
+		Token string `json:"token"`
+	}
+
+	// RoomInfo is what the lobby lists about a room.
+	RoomInfo struct {
+		ID         string `json:"id"`
+		Seats      int    `json:"seats"`
+		Players    int    `json:"players"`
+		Spectators int    `json:"spectators"`
+		Phase      Phase  `json:"phase"`
+	}
+)
+
+func NewLobby(settings Settings) *Lobby {
+	return &Lobby{rooms: make(map[string]*Server), settings: settings}
+}
+
+// CreateRoom starts a new room with the given options. The first seat is
+// kept for the creator, who joins as host with the room's host token.
+func (l *Lobby) CreateRoom(options RoomOptions) (*Server, error) {
+	if options.Seats < minSeats || options.Seats > maxSeats {
+		return nil, errors.New(constants.InvalidSeatCount)
+	}
+	if options.SpectatorDelay < 0 {
+		return nil, errors.New(constants.InvalidSpectatorDelay)
+	}
+	if options.RevealRacks && options.SpectatorDelay < l.settings.MinRevealDelay {
+		return nil, constants.Format(constants.RevealRequiresDelay, l.settings.MinRevealDelay)
+	}
+	if err := l.settings.Rules.Validate(int(options.Seats)); err != nil {
+		return nil, err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.lastID++
+	room := newRoom(strconv.Itoa(l.lastID), options, l.settings)
+	room.hostToken = newToken()
+	room.tokens[0] = room.hostToken
+	l.rooms[room.id] = room
+	room.logger.Info("room created", "seats", options.Seats)
+	go room.Run()
+	return room, nil
+}
+
+// Restore starts every room saved in the storage path.
+func (l *Lobby) Restore() error {
+	if l.settings.StoragePath == "" {
+		return nil
+	}
+	saved, err := readRooms(l.settings.StoragePath)
+	if err != nil {
+		return err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, save := range saved {
+		room, err := restoreRoom(save, l.settings)
+		if err != nil {
+			return err
+		}
+		l.rooms[room.id] = room
+		id, _ := strconv.Atoi(room.id)
+		l.lastID = max(l.lastID, id)
+		room.logger.Info("room restored", "phase", room.phase)
+		go room.Run()
+	}
+	return nil
+}
+
+// Shutdown stops every room, saving it to the storage path when one is set,
+// and waits until their clients have been sent a close message or the
+// context is done.
+func (l *Lobby) Shutdown(ctx context.Context) error {
+	l.mu.Lock()
+	rooms := make([]*Server, 0, len(l.rooms))
+	for _, room := range l.rooms {
+		rooms = append(rooms, room)
+	}
+	l.mu.Unlock()
+	errs := make([]error, 0)
+	for _, room := range rooms {
+		saved, err := room.shutdown()
+		if err == nil && l.settings.StoragePath != "" {
+			err = writeRoom(l.settings.StoragePath, saved)
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	closed := make(chan struct{})
+	go func() {
+		for _, room := range rooms {
+			room.writers.Wait()
+		}
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-ctx.Done():
+		errs = append(errs, ctx.Err())
+	}
+	return errors.Join(errs...)
+}
+
+func (l *Lobby) Room(id string) (*Server, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	room, ok := l.rooms[id]
+	return room, ok
+}
+
+// Rooms lists every room in the order they were created.
+func (l *Lobby) Rooms() []RoomInfo {
+	l.mu.Lock()
+	rooms := make([]*Server, 0, len(l.rooms))
+	for _, room := range l.rooms {
+		rooms = append(rooms, room)
+	}
+	l.mu.Unlock()
+	sort.Slice(rooms, func(i, j int) bool {
+		first, _ := strconv.Atoi(rooms[i].id)
+		second, _ := strconv.Atoi(rooms[j].id)
+		return first < second
+	})
+	infos := make([]RoomInfo, len(rooms))
+	for i, room := range rooms {
+		infos[i] = room.Summary()
+	}
+	return infos
+}
+
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(value)
+}
+
+// ServeRooms lists the rooms in the lobby.
+func ServeRooms(lobby *Lobby, w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, lobby.Rooms())
+}
+
+// ServeCreateRoom creates a room from the form values seats (the lobby's
+// room size when not given), delay, a duration such as 30s, and reveal.
+func ServeCreateRoom(lobby *Lobby, w http.ResponseWriter, r *http.Request) {
+	options := RoomOptions{Seats: lobby.settings.RoomSize}
+	if value := r.FormValue("seats"); value != "" {
+		seats, err := strconv.ParseUint(value, 10, 8)
+		if err != nil {
+			http.Error(w, constants.InvalidSeatCount, http.StatusBadRequest)
+			return
+		}
+		options.Seats = uint(seats)
+	}
+	if value := r.FormValue("delay"); value != "" {
+		delay, err := time.ParseDuration(value)
+		if err != nil {
+			http.Error(w, constants.InvalidSpectatorDelay, http.StatusBadRequest)
+			return
+		}
+		options.SpectatorDelay = delay
+	}
+	if value := r.FormValue("reveal"); value != "" {
+		reveal, err := strconv.ParseBool(value)
+		if err != nil {
+			http.Error(w, constants.InvalidRevealRacks, http.StatusBadRequest)
+			return
+		}
+		options.RevealRacks = reveal
+	}
+	room, err := lobby.CreateRoom(options)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, http.StatusCreated, CreatedRoom{room.Summary(), room.hostToken})
+}
+
+// ServeRoom connects a websocket to the room named in the request path.
+func ServeRoom(lobby *Lobby, w http.ResponseWriter, r *http.Request) {
+	room, ok := lobby.Room(r.PathValue("room"))
+	if !ok {
+		http.Error(w, constants.RoomNotFound, http.StatusNotFound)
+		return
+	}
+	ServeWs(room, w, r)
+}
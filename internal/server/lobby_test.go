@@ -0,0 +1,154 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"lets-play-rummikub/internal/constants"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateRoom(t *testing.T) {
+	t.Run("ShouldCreateRoomsWithTheirOwnGame", func(t *testing.T) {
+		lobby := NewLobby(DefaultSettings())
+		first, err := lobby.CreateRoom(RoomOptions{Seats: 2})
+		assert.NoError(t, err)
+		second, err := lobby.CreateRoom(RoomOptions{Seats: 3})
+		assert.NoError(t, err)
+		assert.NotEqual(t, first.ID(), second.ID())
+		assert.NotSame(t, first.game, second.game)
+		assert.Equal(t, lobby.Rooms(), []RoomInfo{{first.ID(), 2, 0, 0, Waiting}, {second.ID(), 3, 0, 0, Waiting}})
+	})
+	t.Run("ShouldRejectSeatCount", func(t *testing.T) {
+		lobby := NewLobby(DefaultSettings())
+		_, err := lobby.CreateRoom(RoomOptions{})
+		assert.EqualError(t, err, constants.InvalidSeatCount)
+		_, err = lobby.CreateRoom(RoomOptions{Seats: 5})
+		assert.EqualError(t, err, constants.InvalidSeatCount)
+		assert.Empty(t, lobby.Rooms())
+	})
+	t.Run("ShouldRequireDelayToRevealRacks", func(t *testing.T) {
+		lobby := NewLobby(DefaultSettings())
+		tooShort := fmt.Sprintf(constants.RevealRequiresDelay, lobby.settings.MinRevealDelay)
+		_, err := lobby.CreateRoom(RoomOptions{Seats: 2, RevealRacks: true})
+		assert.EqualError(t, err, tooShort)
+		_, err = lobby.CreateRoom(RoomOptions{Seats: 2, SpectatorDelay: time.Nanosecond, RevealRacks: true})
+		assert.EqualError(t, err, tooShort)
+		_, err = lobby.CreateRoom(RoomOptions{Seats: 2, SpectatorDelay: -time.Minute})
+		assert.EqualError(t, err, constants.InvalidSpectatorDelay)
+		room, err := lobby.CreateRoom(RoomOptions{Seats: 2, SpectatorDelay: lobby.settings.MinRevealDelay, RevealRacks: true})
+		assert.NoError(t, err)
+		assert.Equal(t, room.options.SpectatorDelay, lobby.settings.MinRevealDelay)
+		assert.True(t, room.options.RevealRacks)
+	})
+}
+
+func TestServeCreateRoom(t *testing.T) {
+	lobby := NewLobby(DefaultSettings())
+	t.Run("ShouldCreateRoomFromForm", func(t *testing.T) {
+		request := httptest.NewRequest(http.MethodPost, "/rooms", strings.NewReader(url.Values{"seats": {"4"}}.Encode()))
+		request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		recorder := httptest.NewRecorder()
+		ServeCreateRoom(lobby, recorder, request)
+		assert.Equal(t, recorder.Code, http.StatusCreated)
+		var room CreatedRoom
+		assert.NoError(t, json.NewDecoder(recorder.Body).Decode(&room))
+		assert.Equal(t, room.Seats, 4)
+		created, ok := lobby.Room(room.ID)
+		if assert.True(t, ok) {
+			assert.Equal(t, room.Token, created.hostToken)
+		}
+	})
+	t.Run("ShouldRejectInvalidSeats", func(t *testing.T) {
+		request := httptest.NewRequest(http.MethodPost, "/rooms?seats=many", nil)
+		recorder := httptest.NewRecorder()
+		ServeCreateRoom(lobby, recorder, request)
+		assert.Equal(t, recorder.Code, http.StatusBadRequest)
+	})
+	t.Run("ShouldRejectInvalidReveal", func(t *testing.T) {
+		request := httptest.NewRequest(http.MethodPost, "/rooms?delay=5m&reveal=yes", nil)
+		recorder := httptest.NewRecorder()
+		ServeCreateRoom(lobby, recorder, request)
+		assert.Equal(t, recorder.Code, http.StatusBadRequest)
+		assert.Equal(t, strings.TrimSpace(recorder.Body.String()), constants.InvalidRevealRacks)
+	})
+}
+
+func TestServeRoom(t *testing.T) {
+	t.Run("ShouldNotFindMissingRoom", func(t *testing.T) {
+		request := httptest.NewRequest(http.MethodGet, "/ws/42", nil)
+		request.SetPathValue("room", "42")
+		recorder := httptest.NewRecorder()
+		ServeRoom(NewLobby(DefaultSettings()), recorder, request)
+		assert.Equal(t, recorder.Code, http.StatusNotFound)
+	})
+}
+
+func TestShutdown(t *testing.T) {
+	t.Run("ShouldSaveAndRestoreRooms", func(t *testing.T) {
+		settings := DefaultSettings()
+		settings.StoragePath = t.TempDir()
+		lobby := NewLobby(settings)
+		room, _ := lobby.CreateRoom(RoomOptions{Seats: 2, SpectatorDelay: time.Second})
+		client, session := joinSession(t, room, room.hostToken)
+		sendMessage(t, client, CommandMessage, CommandPayload{Command: "name", Input: "Ana"})
+		room.Summary()
+		assert.NoError(t, lobby.Shutdown(context.Background()))
+		_, open := nextMessage(t, client, AckMessage)
+		assert.True(t, open)
+		_, open = nextMessage(t, client)
+		assert.False(t, open)
+
+		restarted := NewLobby(settings)
+		assert.NoError(t, restarted.Restore())
+		restored, ok := restarted.Room(room.ID())
+		if assert.True(t, ok) {
+			assert.Equal(t, restored.options, room.options)
+			_, reclaimed := joinSession(t, restored, session.Token)
+			assert.Equal(t, reclaimed, session)
+			assert.Equal(t, restored.Summary().Players, 1)
+			assert.Equal(t, restored.game.View(0).Players[0].Name, "Ana")
+		}
+		next, _ := restarted.CreateRoom(RoomOptions{Seats: 2})
+		assert.NotEqual(t, next.ID(), room.ID())
+	})
+	t.Run("ShouldRestartTurnClockOnRestore", func(t *testing.T) {
+		settings := DefaultSettings()
+		settings.StoragePath = t.TempDir()
+		lobby := NewLobby(settings)
+		room, _ := lobby.CreateRoom(RoomOptions{Seats: 1})
+		client, _ := joinSession(t, room, room.hostToken)
+		sendMessage(t, client, CommandMessage, CommandPayload{Command: "start"})
+		room.Summary()
+		assert.NoError(t, lobby.Shutdown(context.Background()))
+
+		restarted := NewLobby(settings)
+		assert.NoError(t, restarted.Restore())
+		restored, _ := restarted.Room(room.ID())
+		client, _ = joinSession(t, restored, room.hostToken)
+		metrics.turnDuration.mu.Lock()
+		before := metrics.turnDuration.sum
+		metrics.turnDuration.mu.Unlock()
+		sendMessage(t, client, CommandMessage, CommandPayload{Command: "draw"})
+		nextMessage(t, client, AckMessage)
+		metrics.turnDuration.mu.Lock()
+		observed := metrics.turnDuration.sum - before
+		metrics.turnDuration.mu.Unlock()
+		assert.Less(t, observed, time.Minute.Seconds())
+	})
+	t.Run("ShouldStartEmptyWithoutStorage", func(t *testing.T) {
+		settings := DefaultSettings()
+		settings.StoragePath = filepath.Join(t.TempDir(), "missing")
+		lobby := NewLobby(settings)
+		assert.NoError(t, lobby.Restore())
+		assert.Empty(t, lobby.Rooms())
+	})
+}
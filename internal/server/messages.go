@@ -1,104 +1,153 @@
 package server
 
 import (
-	"fmt"
+	"errors"
 	"lets-play-rummikub/internal/command"
+	"lets-play-rummikub/internal/constants"
+	"lets-play-rummikub/internal/locale"
 )
 
-type Event struct {
-	Command string `json:"command"`
-	Input   string `json:"input"`
+// rejection is an error returned by handleCommand along with the code sent
+// to the client in its nack.
+type rejection struct {
+	code ErrorCode
+	err  error
 }
 
-const (
-	commandError   = string("error performing %s: %s")
-	playerRenamed  = string("your name has been set to: %s")
-	invalidCommand = string("invalid command")
-)
+func (r *rejection) Error() string {
+	return r.err.Error()
+}
+
+func (r *rejection) Unwrap() error {
+	return r.err
+}
+
+// reject returns a rejection with the code for the message formatted from
+// the format string and arguments, which is sent in each client's locale.
+func reject(code ErrorCode, format string, args ...any) error {
+	return &rejection{code, constants.Format(format, args...)}
+}
+
+func (c *Client) sendNotice(format string, args ...any) {
+	c.write(NoticeMessage, TextPayload{c.locale.Sprintf(format, args...)})
+}
+
+func (c *Client) sendError(format string, args ...any) {
+	c.write(ErrorMessage, TextPayload{c.locale.Sprintf(format, args...)})
+}
+
+func (c *Client) ack(id, name string) {
+	c.reply(AckMessage, id, AckPayload{name})
+}
+
+// errorCode returns the code a rejected command is reported with. Errors
+// without a code are rule violations reported by the game.
+func errorCode(err error) ErrorCode {
+	var parseErr *command.ParseError
+	if errors.As(err, &parseErr) {
+		return ParseErrorCode
+	}
+	var rejected *rejection
+	if errors.As(err, &rejected) {
+		return rejected.code
+	}
+	return RuleViolationCode
+}
+
+// newNack describes why the named command was rejected, in the locale.
+func newNack(selected locale.Locale, name string, err error) NackPayload {
+	payload := NackPayload{Command: name, Code: errorCode(err), Message: selected.Localize(err)}
+	var parseErr *command.ParseError
+	if errors.As(err, &parseErr) {
+		payload.Position = parseErr.Position
+		payload.Message = selected.Sprintf(constants.ErrorAtColumn, parseErr.Err, parseErr.Position)
+	}
+	return payload
+}
+
+// nack tells the client why the command with the given ID was rejected.
+func (c *Client) nack(id, name string, err error) {
+	c.reply(NackMessage, id, newNack(c.locale, name, err))
+}
 
-func (c *Client) handleCommand(event Event) {
+func (c *Client) handleCommand(request CommandPayload) error {
 	server, player, game, moveHistory := c.server, c.server.clients[c], c.server.game, c.server.history
-	switch event.Command {
-	case "combine":
-		if game.CurrentPlayer() != player {
-			return
-		}
-		if playerCommand, err := command.Combine(player, game, event.Input); err == nil {
-			playerCommand.Invoke()
-			moveHistory.Push(playerCommand)
-		} else {
-			c.send <- []byte(fmt.Sprintf(commandError, event.Command, err.Error()))
-		}
-	case "insert":
-		if game.CurrentPlayer() != player {
-			return
-		}
-		if playerCommand, err := command.Insert(player, game, event.Input); err == nil {
-			playerCommand.Invoke()
-			moveHistory.Push(playerCommand)
-		} else {
-			c.send <- []byte(fmt.Sprintf(commandError, event.Command, err.Error()))
-		}
-	case "remove":
-		if game.CurrentPlayer() != player {
-			return
-		}
-		if playerCommand, err := command.Remove(game, event.Input); err == nil {
-			playerCommand.Invoke()
-			moveHistory.Push(playerCommand)
-		} else {
-			c.send <- []byte(fmt.Sprintf(commandError, event.Command, err.Error()))
-		}
-	case "split":
-		if game.CurrentPlayer() != player {
-			return
+	if player == nil && request.Command != "locale" {
+		return reject(SpectatorCode, constants.SpectatorCommand)
+	}
+	if hostCommands[request.Command] && player != server.host {
+		return reject(NotHostCode, constants.NotHost)
+	}
+	if _, known := command.Grammar[request.Command]; known && !server.phase.allows(request.Command) {
+		return reject(NotReadyCode, phaseErrors[server.phase])
+	}
+	if command.TurnCommands[request.Command] && game.CurrentPlayer() != player {
+		return reject(NotYourTurnCode, constants.NotYourTurn)
+	}
+	if command.TurnCommands[request.Command] && server.paused {
+		return reject(NotReadyCode, constants.GamePaused)
+	}
+	switch request.Command {
+	case "combine", "insert", "remove", "move", "split", "splitinsert", "rearrange":
+		playerCommand, err := command.New(request.Command, player, game, request.Input)
+		if err != nil {
+			return err
 		}
-		if playerCommand, err := command.Split(game, event.Input); err == nil {
-			playerCommand.Invoke()
-			moveHistory.Push(playerCommand)
-		} else {
-			c.send <- []byte(fmt.Sprintf(commandError, event.Command, err.Error()))
+		if err := playerCommand.Invoke(); err != nil {
+			return err
 		}
+		moveHistory.Push(playerCommand)
 	case "undo":
-		if game.CurrentPlayer() != player {
-			return
-		}
 		command := moveHistory.Pop()
-		if command != nil {
-			command.Undo()
+		if command == nil {
+			return reject(RuleViolationCode, constants.NothingToUndo)
 		}
+		command.Undo()
 	case "end":
-		if game.CurrentPlayer() != player {
-			return
+		if err := game.NextTurn(); err != nil {
+			return err
 		}
-		if ok := game.NextTurn(); ok {
-			moveHistory.Clear()
+		moveHistory.Clear()
+		server.endTurn()
+	case "draw":
+		if err := game.Draw(); err != nil {
+			return err
 		}
+		moveHistory.Clear()
+		server.endTurn()
 	case "start":
-		if !server.gameStarted && len(server.clients) == server.game.TotalPlayers() {
-			server.gameStarted = true
-			game.Notify(fmt.Sprintf("%s's turn\n", game.CurrentPlayer().Name()))
-		} else {
-			c.send <- []byte("not enough players to start game")
-		}
-	case "shuffle":
-		if !server.tilesShuffled {
-			game.Shuffle()
-			server.tilesShuffled = true
-			game.Notify()
+		return server.transition(Dealing)
+	case "rules":
+		return server.setRules(request.Input)
+	case "swap":
+		return server.swapSeats(request.Input)
+	case "kick":
+		return server.kick(request.Input)
+	case "pause":
+		return server.pause(true)
+	case "resume":
+		return server.pause(false)
+	case "abandon":
+		server.history.Clear()
+		return server.transition(Abandoned)
+	case "host":
+		return server.handOff(request.Input)
+	case "name":
+		if named := server.playerNamed(request.Input); named != nil && named != player {
+			return reject(RuleViolationCode, constants.NameTaken, request.Input)
 		}
-	case "deal":
-		if !server.tilesDealt && len(server.clients) == server.game.TotalPlayers() {
-			game.DealPieces()
-			server.tilesDealt = true
-			game.Notify()
-		} else {
-			c.send <- []byte("not enough players connected to deal pieces")
+		command.SetName(player, request.Input).Invoke()
+		c.sendNotice(constants.PlayerRenamed, player.Name())
+		game.Notify()
+	case "locale":
+		selected, ok := locale.Parse(request.Input)
+		if !ok {
+			return reject(ParseErrorCode, constants.UnsupportedLocale, request.Input)
 		}
-	case "name":
-		command.SetName(player, event.Input).Invoke()
-		c.send <- []byte(fmt.Sprintf(playerRenamed, player.Name()))
+		c.locale = selected
+		c.sendNotice(constants.LocaleChanged, string(selected))
 	default:
-		c.send <- []byte(invalidCommand)
+		return reject(UnknownCommandCode, constants.InvalidCommand)
 	}
+	return nil
 }
@@ -0,0 +1,178 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"lets-play-rummikub/internal/command"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type (
+	// counterVec is a Prometheus counter with one value per combination
+	// of label values.
+	counterVec struct {
+		name   string
+		help   string
+		labels []string
+		mu     sync.Mutex
+		values map[string]uint64
+	}
+
+	// histogram is a Prometheus histogram with cumulative buckets.
+	histogram struct {
+		name    string
+		help    string
+		buckets []float64
+		mu      sync.Mutex
+		counts  []uint64
+		count   uint64
+		sum     float64
+	}
+)
+
+// labelSeparator joins label values into a map key. It cannot appear in a
+// command name or result.
+const labelSeparator = "\xff"
+
+var (
+	secondBuckets = []float64{0.0001, 0.0005, 0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1}
+	turnBuckets   = []float64{5, 15, 30, 60, 120, 300, 600, 1800}
+	depthBuckets  = []float64{0, 1, 2, 4, 8, 16, 32, 64, 128}
+)
+
+// metrics are collected by every room in the process and written by
+// ServeMetrics.
+var metrics = struct {
+	clients        atomic.Int64
+	commands       *counterVec
+	commandLatency *histogram
+	turnDuration   *histogram
+	gamesFinished  *counterVec
+	sendQueueDepth *histogram
+}{
+	commands:       newCounterVec("rummikub_commands_total", "Commands handled, by command and result.", "command", "result"),
+	commandLatency: newHistogram("rummikub_command_duration_seconds", "Time the room took to handle a command.", secondBuckets),
+	turnDuration:   newHistogram("rummikub_turn_duration_seconds", "Time from the start of a turn until the player ended it or drew.", turnBuckets),
+	gamesFinished:  newCounterVec("rummikub_games_finished_total", "Games that ended, by outcome.", "outcome"),
+	sendQueueDepth: newHistogram("rummikub_send_queue_depth", "Messages waiting for a client's write pump after each message is queued.", depthBuckets),
+}
+
+func newCounterVec(name, help string, labels ...string) *counterVec {
+	return &counterVec{name: name, help: help, labels: labels, values: make(map[string]uint64)}
+}
+
+func (c *counterVec) inc(values ...string) {
+	c.mu.Lock()
+	c.values[strings.Join(values, labelSeparator)]++
+	c.mu.Unlock()
+}
+
+func (c *counterVec) write(w io.Writer) {
+	c.mu.Lock()
+	keys := make([]string, 0, len(c.values))
+	for key := range c.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", c.name, c.help, c.name)
+	for _, key := range keys {
+		values := strings.Split(key, labelSeparator)
+		pairs := make([]string, len(c.labels))
+		for i, label := range c.labels {
+			pairs[i] = fmt.Sprintf("%s=%q", label, values[i])
+		}
+		fmt.Fprintf(w, "%s{%s} %d\n", c.name, strings.Join(pairs, ","), c.values[key])
+	}
+	c.mu.Unlock()
+}
+
+func newHistogram(name, help string, buckets []float64) *histogram {
+	return &histogram{name: name, help: help, buckets: buckets, counts: make([]uint64, len(buckets))}
+}
+
+func (h *histogram) observe(value float64) {
+	h.mu.Lock()
+	for i, bound := range h.buckets {
+		if value <= bound {
+			h.counts[i]++
+		}
+	}
+	h.count++
+	h.sum += value
+	h.mu.Unlock()
+}
+
+func (h *histogram) write(w io.Writer) {
+	h.mu.Lock()
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s histogram\n", h.name, h.help, h.name)
+	for i, bound := range h.buckets {
+		fmt.Fprintf(w, "%s_bucket{le=%q} %d\n", h.name, formatFloat(bound), h.counts[i])
+	}
+	fmt.Fprintf(w, "%s_bucket{le=\"+Inf\"} %d\n", h.name, h.count)
+	fmt.Fprintf(w, "%s_sum %s\n%s_count %d\n", h.name, formatFloat(h.sum), h.name, h.count)
+	h.mu.Unlock()
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+func writeGauge(w io.Writer, name, help string, value int64) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n%s %d\n", name, help, name, name, value)
+}
+
+// writeRooms writes how many rooms are in each phase, so rooms whose game
+// has finished or been abandoned are not counted with those being played.
+func writeRooms(w io.Writer, rooms []RoomInfo) {
+	counts := make([]int, len(phaseNames))
+	for _, room := range rooms {
+		counts[room.Phase]++
+	}
+	fmt.Fprintf(w, "# HELP rummikub_rooms Rooms in the lobby, by phase.\n# TYPE rummikub_rooms gauge\n")
+	for phase, count := range counts {
+		fmt.Fprintf(w, "rummikub_rooms{phase=%q} %d\n", Phase(phase), count)
+	}
+}
+
+func writeCounter(w io.Writer, name, help string, value uint64) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n%s %d\n", name, help, name, name, value)
+}
+
+// commandLabel keeps the command label to names in the grammar, so clients
+// cannot add a series for every made up command.
+func commandLabel(name string) string {
+	if _, ok := command.Grammar[name]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// observeCommand records a handled command. Rejected commands are counted
+// by the code sent in their nack.
+func observeCommand(name, result string, elapsed time.Duration) {
+	metrics.commands.inc(commandLabel(name), result)
+	metrics.commandLatency.observe(elapsed.Seconds())
+}
+
+// ServeMetrics writes the metrics of every room in the Prometheus text
+// format.
+func ServeMetrics(lobby *Lobby, w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	writeRooms(w, lobby.Rooms())
+	writeGauge(w, "rummikub_clients", "Connected players and spectators.", metrics.clients.Load())
+	metrics.commands.write(w)
+	metrics.commandLatency.write(w)
+	metrics.turnDuration.write(w)
+	metrics.gamesFinished.write(w)
+	metrics.sendQueueDepth.write(w)
+	stats := Backpressure()
+	writeCounter(w, "rummikub_state_coalesced_total", "State snapshots replaced by a newer one before being sent.", stats.Coalesced)
+	writeCounter(w, "rummikub_messages_dropped_total", "Messages dropped because a client's send queue was full.", stats.Dropped)
+	writeCounter(w, "rummikub_clients_lagging_total", "Clients disconnected because their send queue filled up.", stats.Disconnected)
+}
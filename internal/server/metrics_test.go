@@ -0,0 +1,70 @@
+package server
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func commandCount(name, result string) uint64 {
+	metrics.commands.mu.Lock()
+	defer metrics.commands.mu.Unlock()
+	return metrics.commands.values[name+labelSeparator+result]
+}
+
+func TestMetrics(t *testing.T) {
+	t.Run("ShouldWriteCumulativeBuckets", func(t *testing.T) {
+		histogram := newHistogram("test_seconds", "Test.", []float64{1, 5})
+		histogram.observe(0.5)
+		histogram.observe(3)
+		histogram.observe(10)
+		var output bytes.Buffer
+		histogram.write(&output)
+		assert.Equal(t, output.String(), "# HELP test_seconds Test.\n# TYPE test_seconds histogram\n"+
+			"test_seconds_bucket{le=\"1\"} 1\ntest_seconds_bucket{le=\"5\"} 2\ntest_seconds_bucket{le=\"+Inf\"} 3\n"+
+			"test_seconds_sum 13.5\ntest_seconds_count 3\n")
+	})
+	t.Run("ShouldWriteLabelledCounters", func(t *testing.T) {
+		counter := newCounterVec("test_total", "Test.", "command", "result")
+		counter.inc("end", "ok")
+		counter.inc("draw", "not_your_turn")
+		counter.inc("end", "ok")
+		var output bytes.Buffer
+		counter.write(&output)
+		assert.Equal(t, output.String(), "# HELP test_total Test.\n# TYPE test_total counter\n"+
+			"test_total{command=\"draw\",result=\"not_your_turn\"} 1\ntest_total{command=\"end\",result=\"ok\"} 2\n")
+	})
+	t.Run("ShouldCountCommandsByResult", func(t *testing.T) {
+		server := NewServer(1)
+		go server.Run()
+		client, _ := joinSession(t, server, "")
+		named, early, unknown := commandCount("name", "ok"), commandCount("draw", string(NotReadyCode)), commandCount("unknown", string(UnknownCommandCode))
+		sendMessage(t, client, CommandMessage, CommandPayload{Command: "name", Input: "Ana"})
+		sendMessage(t, client, CommandMessage, CommandPayload{Command: "draw"})
+		sendMessage(t, client, CommandMessage, CommandPayload{Command: "dance"})
+		server.Summary()
+		assert.Equal(t, commandCount("name", "ok")-named, uint64(1))
+		assert.Equal(t, commandCount("draw", string(NotReadyCode))-early, uint64(1))
+		assert.Equal(t, commandCount("unknown", string(UnknownCommandCode))-unknown, uint64(1))
+	})
+	t.Run("ShouldServePrometheusText", func(t *testing.T) {
+		lobby := NewLobby(DefaultSettings())
+		lobby.CreateRoom(RoomOptions{Seats: 2})
+		abandoned, _ := lobby.CreateRoom(RoomOptions{Seats: 2})
+		abandoned.call(func() { abandoned.transition(Abandoned) })
+		recorder := httptest.NewRecorder()
+		ServeMetrics(lobby, recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		assert.Contains(t, recorder.Header().Get("Content-Type"), "text/plain")
+		body := recorder.Body.String()
+		assert.Contains(t, body, "rummikub_rooms{phase=\"waiting\"} 1\n")
+		assert.Contains(t, body, "rummikub_rooms{phase=\"abandoned\"} 1\n")
+		assert.Contains(t, body, "rummikub_rooms{phase=\"finished\"} 0\n")
+		for _, name := range []string{"rummikub_clients", "rummikub_commands_total", "rummikub_command_duration_seconds", "rummikub_turn_duration_seconds",
+			"rummikub_games_finished_total", "rummikub_send_queue_depth", "rummikub_messages_dropped_total"} {
+			assert.Contains(t, body, "# TYPE "+name+" ")
+		}
+	})
+}
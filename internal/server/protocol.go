@@ -0,0 +1,192 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"lets-play-rummikub/internal/constants"
+	"time"
+)
+
+// ProtocolVersion is sent with every envelope and must match on messages
+// received from clients.
+const ProtocolVersion = 1
+
+// Limits on fields of messages from clients, checked before the message
+// reaches the game.
+const (
+	maxIDLength      = 64
+	maxCommandLength = 32
+	maxInputLength   = 256
+)
+
+type MessageType string
+
+const (
+	// CommandMessage is sent by clients to play or set up the game.
+	CommandMessage MessageType = "command"
+	// ChatMessage is sent by clients and broadcast to the room, or to one
+	// player when To is set.
+	ChatMessage MessageType = "chat"
+	// StateMessage carries the game as seen from the receiving player's seat.
+	StateMessage MessageType = "state"
+	// NoticeMessage carries informational text for the player.
+	NoticeMessage MessageType = "notice"
+	// ErrorMessage carries text describing why a message was rejected.
+	ErrorMessage MessageType = "error"
+	// SessionMessage carries the seat a client joined, the token that
+	// reclaims it after reconnecting and whether it is the host. It is sent
+	// again when seats are swapped or the host changes. Spectators get seat
+	// -1 and no token.
+	SessionMessage MessageType = "session"
+	// AckMessage confirms a command was applied.
+	AckMessage MessageType = "ack"
+	// NackMessage reports why a command was rejected.
+	NackMessage MessageType = "nack"
+	// PhaseMessage is sent on joining and whenever the room moves to
+	// another phase of its game.
+	PhaseMessage MessageType = "phase"
+)
+
+// ErrorCode identifies why a command or HTTP API request was rejected,
+// independent of the locale the message is written in.
+type ErrorCode string
+
+const (
+	InvalidMessageCode ErrorCode = "invalid_message"
+	UnknownCommandCode ErrorCode = "unknown_command"
+	NotYourTurnCode    ErrorCode = "not_your_turn"
+	ParseErrorCode     ErrorCode = "parse_error"
+	RuleViolationCode  ErrorCode = "rule_violation"
+	NotReadyCode       ErrorCode = "not_ready"
+	SpectatorCode      ErrorCode = "spectator"
+	NotHostCode        ErrorCode = "not_host"
+	NotFoundCode       ErrorCode = "not_found"
+	UnauthorizedCode   ErrorCode = "unauthorized"
+	RoomFullCode       ErrorCode = "room_full"
+	InternalErrorCode  ErrorCode = "internal_error"
+)
+
+type (
+	// Envelope wraps every message sent over the websocket in either
+	// direction. Seq increases by one with every message a sender sends.
+	// ID is chosen by the client and echoed on the ack or nack replying to
+	// that message.
+	Envelope struct {
+		Version int             `json:"v"`
+		Type    MessageType     `json:"type"`
+		Seq     uint64          `json:"seq"`
+		ID      string          `json:"id,omitempty"`
+		Payload json.RawMessage `json:"payload,omitempty"`
+	}
+
+	CommandPayload struct {
+		Command string `json:"command"`
+		Input   string `json:"input"`
+	}
+
+	TextPayload struct {
+		Text string `json:"text"`
+	}
+
+	// ChatPayload is sent by clients with Text and optionally To, the name
+	// of a player to message privately. The server adds From and Time.
+	ChatPayload struct {
+		From string    `json:"from,omitempty"`
+		To   string    `json:"to,omitempty"`
+		Text string    `json:"text"`
+		Time time.Time `json:"time"`
+	}
+
+	SessionPayload struct {
+		Token string `json:"token,omitempty"`
+		Seat  int    `json:"seat"`
+		Host  bool   `json:"host,omitempty"`
+	}
+
+	// PhasePayload carries the phase the room is in and, when it has just
+	// moved, the phase it left.
+	PhasePayload struct {
+		Phase    Phase  `json:"phase"`
+		Previous *Phase `json:"previous,omitempty"`
+	}
+
+	AckPayload struct {
+		Command string `json:"command"`
+	}
+
+	// NackPayload describes a rejected command. Position is the column of
+	// the input the error was found at, or zero when it does not apply.
+	NackPayload struct {
+		Command  string    `json:"command"`
+		Code     ErrorCode `json:"code"`
+		Message  string    `json:"message"`
+		Position int       `json:"position,omitempty"`
+	}
+)
+
+// validator is a payload that checks its own fields once decoded.
+type validator interface {
+	validate() error
+}
+
+// decodeStrict decodes a single JSON value, rejecting fields the value does
+// not have.
+func decodeStrict(data []byte, value any) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(value); err != nil || decoder.More() {
+		return errors.New(constants.InvalidMessage)
+	}
+	return nil
+}
+
+func decodeEnvelope(message []byte) (Envelope, error) {
+	var envelope Envelope
+	if err := decodeStrict(message, &envelope); err != nil {
+		return envelope, err
+	}
+	if envelope.Version != ProtocolVersion {
+		return envelope, errors.New(constants.UnsupportedVersion)
+	}
+	if len(envelope.ID) > maxIDLength {
+		return envelope, errors.New(constants.InvalidMessage)
+	}
+	return envelope, nil
+}
+
+// decodePayload decodes and validates the payload of a client message.
+func decodePayload(raw json.RawMessage, payload validator) error {
+	if len(raw) == 0 {
+		raw = json.RawMessage("{}")
+	}
+	if err := decodeStrict(raw, payload); err != nil {
+		return &rejection{InvalidMessageCode, err}
+	}
+	return payload.validate()
+}
+
+func (p *CommandPayload) validate() error {
+	if p.Command == "" || len(p.Command) > maxCommandLength {
+		return reject(InvalidMessageCode, constants.InvalidMessage)
+	}
+	if len(p.Input) > maxInputLength {
+		return reject(InvalidMessageCode, constants.InputTooLong, maxInputLength)
+	}
+	return nil
+}
+
+func (p *ChatPayload) validate() error {
+	if len(p.To) > maxInputLength {
+		return reject(InvalidMessageCode, constants.InvalidMessage)
+	}
+	return nil
+}
+
+func encodeEnvelope(messageType MessageType, seq uint64, id string, payload any) ([]byte, error) {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(Envelope{ProtocolVersion, messageType, seq, id, raw})
+}
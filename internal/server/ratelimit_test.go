@@ -0,0 +1,32 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiter(t *testing.T) {
+	t.Run("ShouldAllowBurst", func(t *testing.T) {
+		limiter, now := newRateLimiter(1, 3), time.Now()
+		for i := 0; i < 3; i++ {
+			assert.True(t, limiter.allow(now))
+		}
+		assert.False(t, limiter.allow(now))
+	})
+	t.Run("ShouldRefillAtRate", func(t *testing.T) {
+		limiter, now := newRateLimiter(2, 1), time.Now()
+		assert.True(t, limiter.allow(now))
+		assert.False(t, limiter.allow(now.Add(100*time.Millisecond)))
+		assert.True(t, limiter.allow(now.Add(600*time.Millisecond)))
+	})
+	t.Run("ShouldNotRefillPastBurst", func(t *testing.T) {
+		limiter, now := newRateLimiter(10, 2), time.Now()
+		assert.True(t, limiter.allow(now))
+		now = now.Add(time.Hour)
+		assert.True(t, limiter.allow(now))
+		assert.True(t, limiter.allow(now))
+		assert.False(t, limiter.allow(now))
+	})
+}
@@ -1,72 +1,337 @@
 package server
 
 import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"lets-play-rummikub/internal/constants"
 	"lets-play-rummikub/internal/history"
 	"lets-play-rummikub/internal/model"
+	"log/slog"
+	"sync"
+	"time"
 )
 
 type Server struct {
-	gameStarted   bool
-	tilesShuffled bool
-	tilesDealt    bool
-	game          model.Game
-	history       history.Stack[history.Undoable]
-	clients       map[*Client]model.Player
-	receive       chan []byte
-	register      chan *Client
-	unregister    chan *Client
+	id          string
+	phase       Phase
+	paused      bool
+	turnStarted time.Time
+	host        model.Player
+	hostToken   string
+	game        model.Game
+	history     history.Stack[history.Undoable]
+	clients     map[*Client]model.Player
+	tokens      []string
+	spectators  map[*Client]bool
+	options     RoomOptions
+	settings    Settings
+	spectated   spectatorView
+	spectateSeq uint64
+	spectate    chan spectatorView
+	chats       []chatEntry
+	events      []Event
+	lastEvent   uint64
+	receive     chan ClientMessage
+	register    chan *Client
+	unregister  chan *Client
+	summary     chan chan RoomInfo
+	calls       chan func()
+	stopping    chan chan stopped
+	done        chan struct{}
+	writers     sync.WaitGroup
+	logger      *slog.Logger
 }
 
+// spectatorView is a view for spectators numbered in the order the game
+// produced it.
+type spectatorView struct {
+	seq  uint64
+	view model.View
+}
+
+// stopped is the room as it was saved when its run loop stopped.
+type stopped struct {
+	room savedRoom
+	err  error
+}
+
+// newToken returns a random session token for a seat.
+func newToken() string {
+	token := make([]byte, 16)
+	rand.Read(token)
+	return hex.EncodeToString(token)
+}
+
+// ClientMessage is a message read from a client, waiting for the run loop.
 type ClientMessage struct {
 	Client  *Client
 	Message []byte
 }
 
+// NewServer creates a room outside of any lobby, with default settings.
 func NewServer(totalPlayers uint) *Server {
+	return newRoom("", RoomOptions{Seats: totalPlayers}, DefaultSettings())
+}
+
+func newRoom(id string, options RoomOptions, settings Settings) *Server {
 	server := &Server{
-		game:       model.NewGame(totalPlayers),
+		id:         id,
+		options:    options,
+		settings:   settings,
+		game:       model.NewGame(options.Seats),
 		clients:    make(map[*Client]model.Player),
-		receive:    make(chan []byte),
+		tokens:     make([]string, options.Seats),
+		spectators: make(map[*Client]bool),
+		spectate:   make(chan spectatorView),
+		receive:    make(chan ClientMessage),
 		register:   make(chan *Client),
 		unregister: make(chan *Client),
+		summary:    make(chan chan RoomInfo),
+		calls:      make(chan func()),
+		stopping:   make(chan chan stopped),
+		done:       make(chan struct{}),
 		history:    history.NewStack[history.Undoable](),
+		logger:     slog.Default().With("room", id),
 	}
+	server.game.SetRules(settings.Rules)
 	server.game.SetNotifier(server)
+	server.host = server.game.Player(0)
+	server.spectated.view = server.game.SpectatorView(false)
 	return server
 }
 
-func (s *Server) Notify(message ...string) {
+// ID returns the room ID the server was created with in the lobby.
+func (s *Server) ID() string {
+	return s.id
+}
+
+// Summary asks the run loop for the room's public details.
+func (s *Server) Summary() RoomInfo {
+	reply := make(chan RoomInfo)
+	select {
+	case s.summary <- reply:
+		return <-reply
+	case <-s.done:
+		return RoomInfo{ID: s.id}
+	}
+}
+
+// call runs f on the run loop and waits for it to return, so HTTP handlers
+// can use the room like messages from its clients do.
+func (s *Server) call(f func()) error {
+	done := make(chan struct{})
+	select {
+	case s.calls <- func() { f(); close(done) }:
+		<-done
+		return nil
+	case <-s.done:
+		return reject(NotFoundCode, constants.RoomStopped)
+	}
+}
+
+// shutdown saves the room and disconnects every client, then ends its run
+// loop.
+func (s *Server) shutdown() (savedRoom, error) {
+	reply := make(chan stopped)
+	select {
+	case s.stopping <- reply:
+		result := <-reply
+		return result.room, result.err
+	case <-s.done:
+		return savedRoom{}, errors.New(constants.RoomStopped)
+	}
+}
+
+// Notify sends every client the view from their own seat, followed by any
+// messages for the current player.
+func (s *Server) Notify(message ...constants.Message) {
 	for client, player := range s.clients {
-		gameState, err := s.game.MarshalJSON()
-		if err == nil {
-			client.send <- gameState
-		}
-		playerState, err := player.MarshalJSON()
-		if err == nil {
-			client.send <- playerState
-		}
+		client.write(StateMessage, s.game.View(s.seat(player)))
 		if s.game.CurrentPlayer() == player {
 			for _, m := range message {
-				client.send <- []byte(m)
+				client.write(NoticeMessage, TextPayload{client.locale.Localize(m)})
 			}
 		}
 	}
+	s.spectateSeq++
+	view := spectatorView{s.spectateSeq, s.game.SpectatorView(s.options.RevealRacks)}
+	if s.options.SpectatorDelay == 0 {
+		s.broadcastSpectators(view)
+		return
+	}
+	time.AfterFunc(s.options.SpectatorDelay, func() {
+		select {
+		case s.spectate <- view:
+		case <-s.done:
+		}
+	})
 }
 
+// broadcastSpectators sends every spectator the view and keeps it for
+// spectators who join later, so they never see ahead of the delay. Delayed
+// views may arrive out of order, so a view older than the one already sent
+// is dropped.
+func (s *Server) broadcastSpectators(view spectatorView) {
+	if view.seq < s.spectated.seq {
+		return
+	}
+	s.spectated = view
+	for client := range s.spectators {
+		client.write(StateMessage, view.view)
+	}
+}
+
+// claimSeat returns the seat issued the given session token, disconnecting
+// any client still holding it, or issues a token for the first seat nobody
+// has joined yet. It returns SpectatorSeat when every seat is taken.
+func (s *Server) claimSeat(token string) int {
+	if seat := s.seatHolding(token); seat != model.SpectatorSeat {
+		for client, player := range s.clients {
+			if player == s.game.Player(seat) {
+				s.disconnect(client)
+			}
+		}
+		return seat
+	}
+	for seat, issued := range s.tokens {
+		if issued == "" {
+			s.tokens[seat] = newToken()
+			return seat
+		}
+	}
+	return model.SpectatorSeat
+}
+
+// seatHolding returns the seat issued the given session token, or
+// SpectatorSeat.
+func (s *Server) seatHolding(token string) int {
+	for seat, issued := range s.tokens {
+		if token != "" && token == issued {
+			return seat
+		}
+	}
+	return model.SpectatorSeat
+}
+
+// seatOf returns the seat of a connected client, or SpectatorSeat.
+func (s *Server) seatOf(client *Client) int {
+	if player, seated := s.clients[client]; seated {
+		return s.seat(player)
+	}
+	return model.SpectatorSeat
+}
+
+// seat returns the index of the player in the game, or -1.
+func (s *Server) seat(player model.Player) int {
+	for i := 0; i < s.game.TotalPlayers(); i++ {
+		if s.game.Player(i) == player {
+			return i
+		}
+	}
+	return -1
+}
+
+// Run owns the room: the game, history and clients are only ever touched
+// from this goroutine.
 func (s *Server) Run() {
 	for {
 		select {
 		case client := <-s.register:
-			s.clients[client] = s.game.Player(len(s.clients))
-			currentBoard, err := s.game.MarshalJSON()
-			if err == nil {
-				client.send <- currentBoard
-			}
+			s.join(client)
 		case client := <-s.unregister:
-			if _, ok := s.clients[client]; ok {
-				delete(s.clients, client)
-				close(client.send)
+			s.disconnect(client)
+		case message := <-s.receive:
+			if s.isConnected(message.Client) {
+				message.Client.handleMessage(message.Message)
 			}
+		case view := <-s.spectate:
+			s.broadcastSpectators(view)
+		case reply := <-s.summary:
+			reply <- RoomInfo{s.id, s.game.TotalPlayers(), len(s.clients), len(s.spectators), s.phase}
+		case call := <-s.calls:
+			call()
+		case reply := <-s.stopping:
+			s.logger.Info("stopping room", "phase", s.phase)
+			room, err := s.save()
+			for client := range s.clients {
+				s.disconnect(client)
+			}
+			for client := range s.spectators {
+				s.disconnect(client)
+			}
+			close(s.done)
+			reply <- stopped{room, err}
+			return
 		}
+		s.disconnectLagging()
+		s.checkSeats()
 	}
-}
\ No newline at end of file
+}
+
+func (s *Server) join(client *Client) {
+	seat := model.SpectatorSeat
+	if !client.spectate {
+		seat = s.claimSeat(client.token)
+	}
+	metrics.clients.Add(1)
+	s.logger.Info("client joined", "seat", seat)
+	if seat == model.SpectatorSeat {
+		s.spectators[client] = true
+		client.write(SessionMessage, SessionPayload{Seat: seat})
+		client.write(PhaseMessage, PhasePayload{Phase: s.phase})
+		client.write(StateMessage, s.spectated.view)
+		s.sendScrollback(client, true)
+		return
+	}
+	s.clients[client] = s.game.Player(seat)
+	s.record(Event{Type: JoinedEvent, Seat: seat})
+	s.sendSession(client)
+	client.write(PhaseMessage, PhasePayload{Phase: s.phase})
+	client.write(StateMessage, s.game.View(seat))
+	s.sendScrollback(client, false)
+}
+
+func (s *Server) isConnected(client *Client) bool {
+	_, seated := s.clients[client]
+	return seated || s.spectators[client]
+}
+
+// disconnect removes the client from the room and closes its queue, which
+// makes its write pump close the connection. A detached client is only
+// removed, as it never joined.
+func (s *Server) disconnect(client *Client) {
+	if !s.isConnected(client) {
+		return
+	}
+	if client.detached {
+		delete(s.clients, client)
+		return
+	}
+	s.logger.Info("client left", "seat", s.seatOf(client))
+	if player, seated := s.clients[client]; seated {
+		s.record(Event{Type: LeftEvent, Seat: s.seat(player)})
+	}
+	delete(s.clients, client)
+	delete(s.spectators, client)
+	close(client.send)
+	metrics.clients.Add(-1)
+}
+
+// disconnectLagging drops clients whose queue filled up.
+func (s *Server) disconnectLagging() {
+	for client := range s.clients {
+		if client.lagging {
+			s.logger.Warn("disconnecting client with a full send queue", "seat", s.seatOf(client))
+			s.disconnect(client)
+			backpressure.disconnected.Add(1)
+		}
+	}
+	for client := range s.spectators {
+		if client.lagging {
+			s.logger.Warn("disconnecting client with a full send queue", "seat", s.seatOf(client))
+			s.disconnect(client)
+			backpressure.disconnected.Add(1)
+		}
+	}
+}
@@ -0,0 +1,262 @@
+package server
+
+import (
+	"encoding/json"
+	"lets-play-rummikub/internal/constants"
+	"lets-play-rummikub/internal/model"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestClient(server *Server, token string) *Client {
+	return &Client{server: server, send: make(chan outgoing, 256), state: newStateSlot(), token: token}
+}
+
+// nextMessage returns the next envelope of any of the given types queued
+// for the client, skipping any others.
+func nextMessage(t *testing.T, client *Client, messageTypes ...MessageType) (Envelope, bool) {
+	t.Helper()
+	for {
+		var message outgoing
+		select {
+		case queued, ok := <-client.send:
+			if !ok {
+				return Envelope{}, false
+			}
+			if message, ok = client.resolve(queued); !ok {
+				continue
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("no %v message received", messageTypes)
+		}
+		encoded, err := client.encode(message)
+		assert.NoError(t, err)
+		var envelope Envelope
+		assert.NoError(t, json.Unmarshal(encoded, &envelope))
+		if slices.Contains(messageTypes, envelope.Type) {
+			return envelope, true
+		}
+	}
+}
+
+// sendMessage queues a message from the client on the run loop, as if it
+// had been read from the connection.
+func sendMessage(t *testing.T, client *Client, messageType MessageType, payload any) {
+	t.Helper()
+	message, err := encodeEnvelope(messageType, 1, "1", payload)
+	assert.NoError(t, err)
+	client.server.receive <- ClientMessage{client, message}
+}
+
+func joinSession(t *testing.T, server *Server, token string) (*Client, SessionPayload) {
+	t.Helper()
+	client := newTestClient(server, token)
+	server.register <- client
+	envelope, ok := nextMessage(t, client, SessionMessage)
+	assert.True(t, ok)
+	var session SessionPayload
+	assert.NoError(t, json.Unmarshal(envelope.Payload, &session))
+	return client, session
+}
+
+func TestRegister(t *testing.T) {
+	t.Run("ShouldIssueTokenPerSeat", func(t *testing.T) {
+		server := NewServer(2)
+		go server.Run()
+		_, first := joinSession(t, server, "")
+		_, second := joinSession(t, server, "")
+		assert.Equal(t, first.Seat, 0)
+		assert.Equal(t, second.Seat, 1)
+		assert.NotEmpty(t, first.Token)
+		assert.NotEqual(t, first.Token, second.Token)
+	})
+	t.Run("ShouldReclaimSeatWithToken", func(t *testing.T) {
+		server := NewServer(2)
+		go server.Run()
+		first, session := joinSession(t, server, "")
+		joinSession(t, server, "")
+		server.unregister <- first
+		_, reclaimed := joinSession(t, server, session.Token)
+		assert.Equal(t, reclaimed, session)
+		assert.Equal(t, server.Summary().Players, 2)
+	})
+	t.Run("ShouldNotReuseHeldSeat", func(t *testing.T) {
+		server := NewServer(2)
+		go server.Run()
+		first, _ := joinSession(t, server, "")
+		joinSession(t, server, "")
+		server.unregister <- first
+		_, late := joinSession(t, server, "")
+		assert.Equal(t, late, SessionPayload{Seat: model.SpectatorSeat})
+		assert.Equal(t, server.Summary().Spectators, 1)
+	})
+	t.Run("ShouldDisconnectStaleClientOnReclaim", func(t *testing.T) {
+		server := NewServer(2)
+		go server.Run()
+		stale, session := joinSession(t, server, "")
+		_, reclaimed := joinSession(t, server, session.Token)
+		assert.Equal(t, reclaimed.Seat, session.Seat)
+		_, ok := nextMessage(t, stale, SessionMessage)
+		assert.False(t, ok)
+		assert.Equal(t, server.Summary().Players, 1)
+	})
+}
+
+func receiveView(t *testing.T, client *Client) model.View {
+	t.Helper()
+	envelope, ok := nextMessage(t, client, StateMessage)
+	assert.True(t, ok)
+	var view model.View
+	assert.NoError(t, json.Unmarshal(envelope.Payload, &view))
+	return view
+}
+
+func TestSpectators(t *testing.T) {
+	t.Run("ShouldSendBoardWithoutRacks", func(t *testing.T) {
+		server := NewServer(1)
+		go server.Run()
+		player, _ := joinSession(t, server, "")
+		spectator := newTestClient(server, "")
+		spectator.spectate = true
+		server.register <- spectator
+		receiveView(t, spectator)
+		sendMessage(t, player, CommandMessage, CommandPayload{Command: "start"})
+		view := receiveView(t, spectator)
+		assert.Equal(t, view.Seat, model.SpectatorSeat)
+		assert.Empty(t, view.Rack)
+		assert.Nil(t, view.Racks)
+		assert.Equal(t, view.Players[0].Rack, 14)
+	})
+	t.Run("ShouldDelayAndRevealRacks", func(t *testing.T) {
+		server := NewServer(1)
+		server.options.SpectatorDelay, server.options.RevealRacks = 50*time.Millisecond, true
+		go server.Run()
+		player, _ := joinSession(t, server, "")
+		spectator := newTestClient(server, "")
+		spectator.spectate = true
+		server.register <- spectator
+		receiveView(t, spectator)
+		notified := time.Now()
+		sendMessage(t, player, CommandMessage, CommandPayload{Command: "start"})
+		view := receiveView(t, spectator)
+		assert.GreaterOrEqual(t, time.Since(notified), server.options.SpectatorDelay)
+		if assert.Len(t, view.Racks, 1) {
+			assert.Len(t, view.Racks[0], 14)
+		}
+	})
+	t.Run("ShouldDropDelayedViewsArrivingOutOfOrder", func(t *testing.T) {
+		server := NewServer(1)
+		server.broadcastSpectators(spectatorView{2, model.View{Pool: 77}})
+		server.broadcastSpectators(spectatorView{1, model.View{Pool: 78}})
+		assert.Equal(t, server.spectated.view.Pool, 77)
+		server.broadcastSpectators(spectatorView{3, model.View{Pool: 76}})
+		assert.Equal(t, server.spectated.view.Pool, 76)
+	})
+	t.Run("ShouldRejectSpectatorCommands", func(t *testing.T) {
+		server := NewServer(1)
+		go server.Run()
+		spectator := newTestClient(server, "")
+		spectator.spectate = true
+		server.register <- spectator
+		sendMessage(t, spectator, CommandMessage, CommandPayload{Command: "start"})
+		envelope, _ := nextMessage(t, spectator, NackMessage)
+		var nack NackPayload
+		assert.NoError(t, json.Unmarshal(envelope.Payload, &nack))
+		assert.Equal(t, nack.Code, SpectatorCode)
+		assert.Equal(t, nack.Message, constants.SpectatorCommand)
+	})
+}
+
+func TestRun(t *testing.T) {
+	t.Run("ShouldDisconnectClientWithFullQueue", func(t *testing.T) {
+		server := NewServer(2)
+		go server.Run()
+		before := Backpressure()
+		client := &Client{server: server, send: make(chan outgoing), state: newStateSlot()}
+		server.register <- client
+		assert.Equal(t, server.Summary().Players, 0)
+		_, ok := <-client.send
+		assert.False(t, ok)
+		after := Backpressure()
+		assert.Equal(t, after.Disconnected-before.Disconnected, uint64(1))
+		assert.Greater(t, after.Dropped, before.Dropped)
+	})
+	t.Run("ShouldCoalesceStateSnapshots", func(t *testing.T) {
+		server := NewServer(1)
+		go server.Run()
+		client, _ := joinSession(t, server, "")
+		client.state.take()
+		before := Backpressure()
+		sendMessage(t, client, CommandMessage, CommandPayload{Command: "name", Input: "Ana"})
+		sendMessage(t, client, CommandMessage, CommandPayload{Command: "name", Input: "Bea"})
+		server.Summary()
+		assert.Equal(t, receiveView(t, client).Players[0].Name, "Bea")
+		_, pending := client.state.take()
+		assert.False(t, pending)
+		assert.Equal(t, Backpressure().Coalesced-before.Coalesced, uint64(1))
+	})
+	t.Run("ShouldSendStateBeforeAck", func(t *testing.T) {
+		server := NewServer(1)
+		go server.Run()
+		client, _ := joinSession(t, server, "")
+		server.Summary()
+		for len(client.send) > 0 {
+			client.resolve(<-client.send)
+		}
+		sendMessage(t, client, CommandMessage, CommandPayload{Command: "name", Input: "Ana"})
+		server.Summary()
+		var types []MessageType
+		for len(client.send) > 0 {
+			if message, ok := client.resolve(<-client.send); ok {
+				types = append(types, message.messageType)
+			}
+		}
+		assert.Equal(t, types, []MessageType{NoticeMessage, StateMessage, AckMessage})
+	})
+	t.Run("ShouldFormatNoticesInClientLocale", func(t *testing.T) {
+		server := NewServer(1)
+		go server.Run()
+		client, _ := joinSession(t, server, "")
+		sendMessage(t, client, CommandMessage, CommandPayload{Command: "locale", Input: "es"})
+		sendMessage(t, client, CommandMessage, CommandPayload{Command: "name", Input: constants.Index})
+		var notices []string
+		for i := 0; i < 2; i++ {
+			envelope, _ := nextMessage(t, client, NoticeMessage)
+			var notice TextPayload
+			assert.NoError(t, json.Unmarshal(envelope.Payload, &notice))
+			notices = append(notices, notice.Text)
+		}
+		assert.Equal(t, notices, []string{"tu idioma ahora es: es", "tu nombre ahora es: index"})
+	})
+	t.Run("ShouldIgnoreMessagesFromDisconnectedClients", func(t *testing.T) {
+		server := NewServer(1)
+		go server.Run()
+		client, _ := joinSession(t, server, "")
+		server.unregister <- client
+		sendMessage(t, client, CommandMessage, CommandPayload{Command: "name", Input: "Ana"})
+		assert.Equal(t, server.Summary().Players, 0)
+	})
+	t.Run("ShouldHandleClientsConcurrently", func(t *testing.T) {
+		server := NewServer(4)
+		go server.Run()
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				client, _ := joinSession(t, server, "")
+				for j := 0; j < 10; j++ {
+					sendMessage(t, client, ChatMessage, ChatPayload{Text: "hi"})
+					sendMessage(t, client, CommandMessage, CommandPayload{Command: "end"})
+				}
+				server.unregister <- client
+			}()
+		}
+		wg.Wait()
+		assert.Equal(t, server.Summary(), RoomInfo{Seats: 4, Phase: Ready})
+	})
+}
@@ -0,0 +1,57 @@
+package server
+
+import (
+	"lets-play-rummikub/internal/model"
+	"time"
+)
+
+// Settings apply to every room in a lobby.
+type Settings struct {
+	// RoomSize is the number of seats in rooms created without a size.
+	RoomSize uint
+	Rules    model.Rules
+	// WriteWait is the time allowed to write a message to a client.
+	WriteWait time.Duration
+	// PongWait is the time allowed between pongs before a client is dropped.
+	PongWait time.Duration
+	// MinRevealDelay is the shortest spectator delay a room may reveal
+	// racks with. It should cover at least a full turn, or a player
+	// watching as a spectator could see the racks still in play.
+	MinRevealDelay time.Duration
+	// MaxMessageSize is the largest message accepted from a client.
+	MaxMessageSize int64
+	// SendQueue is how many messages may wait for a client's write pump.
+	// A client whose queue fills up has fallen too far behind and is
+	// disconnected rather than holding up the room.
+	SendQueue int
+	// RateLimit is how many messages per second a client may send on
+	// average, with bursts of up to RateBurst.
+	RateLimit float64
+	RateBurst int
+	// AllowedOrigins are the origins browsers may connect from. When
+	// empty, only pages served by this server may connect.
+	AllowedOrigins []string
+	// StoragePath is the directory rooms are saved to on shutdown, or
+	// empty to not save them.
+	StoragePath string
+}
+
+func DefaultSettings() Settings {
+	return Settings{
+		RoomSize:       2,
+		Rules:          model.DefaultRules(),
+		WriteWait:      10 * time.Second,
+		PongWait:       60 * time.Second,
+		MinRevealDelay: 2 * time.Minute,
+		MaxMessageSize: 512,
+		SendQueue:      64,
+		RateLimit:      10,
+		RateBurst:      20,
+	}
+}
+
+// pingPeriod is how often clients are pinged, often enough that a pong
+// arrives before PongWait runs out.
+func (s Settings) pingPeriod() time.Duration {
+	return (s.PongWait * 9) / 10
+}
@@ -0,0 +1,105 @@
+package server
+
+import (
+	"encoding/json"
+	"lets-play-rummikub/internal/model"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const roomFilePrefix = "room-"
+
+// savedRoom is a room written to storage on shutdown. Session tokens are
+// kept so players can reclaim their seats once the server is back. Undo
+// history and chat are not kept.
+type savedRoom struct {
+	ID      string          `json:"id"`
+	Options RoomOptions     `json:"options"`
+	Tokens  []string        `json:"tokens"`
+	Phase   Phase           `json:"phase"`
+	Paused  bool            `json:"paused"`
+	Host    int             `json:"host"`
+	Game    json.RawMessage `json:"game"`
+}
+
+func (s *Server) save() (savedRoom, error) {
+	game, err := s.game.MarshalState()
+	if err != nil {
+		return savedRoom{}, err
+	}
+	return savedRoom{s.id, s.options, s.tokens, s.phase, s.paused, s.seat(s.host), game}, nil
+}
+
+func restoreRoom(saved savedRoom, settings Settings) (*Server, error) {
+	game, err := model.RestoreGame(saved.Game)
+	if err != nil {
+		return nil, err
+	}
+	room := newRoom(saved.ID, saved.Options, settings)
+	room.game = game
+	room.game.SetNotifier(room)
+	room.host = game.Player(saved.Host)
+	if room.host == nil {
+		room.host = game.Player(0)
+	}
+	room.spectated.view = room.game.SpectatorView(false)
+	if len(saved.Tokens) == game.TotalPlayers() {
+		room.tokens = saved.Tokens
+	}
+	room.phase, room.paused = saved.Phase, saved.Paused
+	if room.phase == InTurn {
+		// The saved turn's start is not kept, so its clock restarts.
+		room.turnStarted = time.Now()
+	}
+	return room, nil
+}
+
+func roomFile(path, id string) string {
+	return filepath.Join(path, roomFilePrefix+id+".json")
+}
+
+func writeRoom(path string, saved savedRoom) error {
+	data, err := json.Marshal(saved)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(path, 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(roomFile(path, saved.ID), data, 0o600)
+}
+
+// readRooms loads every room saved in path. A missing directory holds no
+// rooms.
+func readRooms(path string) ([]savedRoom, error) {
+	entries, err := os.ReadDir(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	rooms := make([]savedRoom, 0)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, roomFilePrefix) || filepath.Ext(name) != ".json" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(path, name))
+		if err != nil {
+			return nil, err
+		}
+		var saved savedRoom
+		if err := json.Unmarshal(data, &saved); err != nil {
+			return nil, err
+		}
+		if _, err := strconv.Atoi(saved.ID); err != nil {
+			continue
+		}
+		rooms = append(rooms, saved)
+	}
+	return rooms, nil
+}
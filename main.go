@@ -1,35 +1,126 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"lets-play-rummikub/internal/config"
 	"lets-play-rummikub/internal/server"
+	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 )
 
-func serveHome(w http.ResponseWriter, r *http.Request) {
-	fmt.Println(r.URL)
-	if r.URL.Path != "/" {
-		http.Error(w, "Not found", http.StatusNotFound)
-		return
+func serveHome(template string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		slog.Debug("serving page", "url", r.URL.String())
+		if r.URL.Path != "/" {
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		http.ServeFile(w, r, template)
 	}
-	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
+}
+
+func newLogger(cfg config.Log) *slog.Logger {
+	level, _ := cfg.SlogLevel()
+	options := &slog.HandlerOptions{Level: level}
+	if cfg.Format == "json" {
+		return slog.New(slog.NewJSONHandler(os.Stderr, options))
+	}
+	return slog.New(slog.NewTextHandler(os.Stderr, options))
+}
+
+func settings(cfg config.Config) server.Settings {
+	return server.Settings{
+		RoomSize:       cfg.RoomSize,
+		Rules:          cfg.Rules,
+		WriteWait:      time.Duration(cfg.Timers.WriteWait),
+		PongWait:       time.Duration(cfg.Timers.PongWait),
+		MinRevealDelay: time.Duration(cfg.Timers.MinRevealDelay),
+		MaxMessageSize: cfg.Limits.MaxMessageSize,
+		SendQueue:      cfg.Limits.SendQueue,
+		RateLimit:      cfg.Limits.RateLimit,
+		RateBurst:      cfg.Limits.RateBurst,
+		AllowedOrigins: cfg.AllowedOrigins,
+		StoragePath:    cfg.StoragePath,
 	}
-	http.ServeFile(w, r, "template/home.html")
 }
 
 func main() {
-	gameServer := server.NewServer(2)
-	go gameServer.Run()
-	http.HandleFunc("/", serveHome)
-	http.HandleFunc("/ws", func(w http.ResponseWriter, r *http.Request) {
-		server.ServeWs(gameServer, w, r)
-	})
-	err := http.ListenAndServe(":8080", nil)
+	cfg, err := config.Load(os.Args[1:], os.Getenv)
 	if err != nil {
-		fmt.Print("ListenAndServe: ", err)
-		os.Exit(0)
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	slog.SetDefault(newLogger(cfg.Log))
+
+	lobby := server.NewLobby(settings(cfg))
+	if err := lobby.Restore(); err != nil {
+		slog.Error("restoring rooms", "path", cfg.StoragePath, "error", err)
+		os.Exit(1)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", serveHome(cfg.Template))
+	mux.HandleFunc("GET /rooms", func(w http.ResponseWriter, r *http.Request) {
+		server.ServeRooms(lobby, w, r)
+	})
+	mux.HandleFunc("POST /rooms", func(w http.ResponseWriter, r *http.Request) {
+		server.ServeCreateRoom(lobby, w, r)
+	})
+	mux.HandleFunc("/ws/{room}", func(w http.ResponseWriter, r *http.Request) {
+		server.ServeRoom(lobby, w, r)
+	})
+	mux.HandleFunc("GET /metrics", func(w http.ResponseWriter, r *http.Request) {
+		server.ServeMetrics(lobby, w, r)
+	})
+	mux.HandleFunc("GET /api/games", func(w http.ResponseWriter, r *http.Request) {
+		server.ServeGames(lobby, w, r)
+	})
+	mux.HandleFunc("POST /api/games", func(w http.ResponseWriter, r *http.Request) {
+		server.ServeCreateGame(lobby, w, r)
+	})
+	mux.HandleFunc("GET /api/games/{id}", func(w http.ResponseWriter, r *http.Request) {
+		server.ServeGame(lobby, w, r)
+	})
+	mux.HandleFunc("POST /api/games/{id}/seats", func(w http.ResponseWriter, r *http.Request) {
+		server.ServeJoinGame(lobby, w, r)
+	})
+	mux.HandleFunc("POST /api/games/{id}/moves", func(w http.ResponseWriter, r *http.Request) {
+		server.ServeMove(lobby, w, r)
+	})
+	mux.HandleFunc("GET /api/games/{id}/events", func(w http.ResponseWriter, r *http.Request) {
+		server.ServeEvents(lobby, w, r)
+	})
+	mux.HandleFunc("GET /api/openapi.json", server.ServeOpenAPI)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	httpServer := &http.Server{Addr: cfg.Addr, Handler: mux}
+	go func() {
+		slog.Info("listening", "addr", cfg.Addr)
+		if err := httpServer.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("ListenAndServe", "error", err)
+			stop()
+		}
+	}()
+	<-ctx.Done()
+
+	slog.Info("shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.Timers.ShutdownTimeout))
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		slog.Error("closing listener", "error", err)
+	}
+	if err := lobby.Shutdown(shutdownCtx); err != nil {
+		slog.Error("saving rooms", "path", cfg.StoragePath, "error", err)
+		os.Exit(1)
 	}
 }